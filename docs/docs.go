@@ -15,31 +15,22 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/auth/login": {
+        "/api/account/delete/cancel": {
             "post": {
-                "description": "login",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "cancel the scheduled account deletion",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
-                ],
-                "summary": "Login",
-                "operationId": "login",
-                "parameters": [
-                    {
-                        "description": "credentials",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/core.AuthLogin"
-                        }
-                    }
+                    "Account"
                 ],
+                "summary": "CancelAccountDeletion",
+                "operationId": "cancelAccountDeletion",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -59,9 +50,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/auth/refresh": {
+        "/api/account/delete/confirm": {
             "post": {
-                "description": "refresh",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "confirm the account deletion with the code, the account is deleted after a grace period unless the deletion is cancelled",
                 "consumes": [
                     "application/json"
                 ],
@@ -69,15 +65,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Account"
+                ],
+                "summary": "ConfirmAccountDeletion",
+                "operationId": "confirmAccountDeletion",
+                "parameters": [
+                    {
+                        "description": "confirmation code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.ConfirmAccountDeletionReq"
+                        }
+                    }
                 ],
-                "summary": "Refresh",
-                "operationId": "refresh",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/rest.tokenResponse"
+                            "$ref": "#/definitions/core.AccountDeletionResp"
                         }
                     },
                     "400": {
@@ -95,31 +102,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/auth/register": {
+        "/api/account/delete/request": {
             "post": {
-                "description": "register",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "send the code confirming the account deletion to the phone of the user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
-                ],
-                "summary": "Register",
-                "operationId": "register",
-                "parameters": [
-                    {
-                        "description": "credentials",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/core.AuthRegister"
-                        }
-                    }
+                    "Account"
                 ],
+                "summary": "RequestAccountDeletion",
+                "operationId": "requestAccountDeletion",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -139,34 +137,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/auth/verify/{code}": {
+        "/api/account/export": {
             "get": {
-                "description": "verify",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
                 ],
+                "description": "download a zip archive with the profile, sessions, chat memberships and messages of the user",
                 "produces": [
-                    "application/json"
+                    "application/octet-stream"
                 ],
                 "tags": [
-                    "Auth"
-                ],
-                "summary": "Verify",
-                "operationId": "verify",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "code",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Account"
                 ],
+                "summary": "ExportAccountData",
+                "operationId": "exportAccountData",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/rest.tokenResponse"
+                            "type": "file"
                         }
                     },
                     "400": {
@@ -184,14 +175,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/chat/default/create": {
+        "/api/account/phone/confirm": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "create chat default",
+                "description": "confirm the phone change with the codes, every other session is logged out and contacts holding the old phone learn the new one",
                 "consumes": [
                     "application/json"
                 ],
@@ -199,24 +190,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Chat"
+                    "Account"
                 ],
-                "summary": "CreateChatDefault",
-                "operationId": "createChatDefault",
+                "summary": "ConfirmPhoneChange",
+                "operationId": "confirmPhoneChange",
                 "parameters": [
                     {
-                        "description": "create chat default",
+                        "description": "confirmation codes",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/core.CreateDefaultChatReq"
+                            "$ref": "#/definitions/core.ConfirmPhoneChangeReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.PhoneChangedResp"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -224,6 +218,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/rest.errorResponse"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -233,29 +233,34 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/chat/default/delete/{chatId}": {
-            "delete": {
+        "/api/account/phone/request": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "delete chat default",
+                "description": "send a code to the new phone and, with verify_old, another one to the current phone",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Chat"
+                    "Account"
                 ],
-                "summary": "DeleteChatDefault",
-                "operationId": "deleteChatDefault",
+                "summary": "RequestPhoneChange",
+                "operationId": "requestPhoneChange",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "chat id",
-                        "name": "chatId",
-                        "in": "path",
-                        "required": true
+                        "description": "new phone",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.ChangePhoneReq"
+                        }
                     }
                 ],
                 "responses": {
@@ -268,6 +273,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/rest.errorResponse"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -277,29 +288,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/chat/group/admin/delete/{chatId}": {
-            "delete": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
+        "/api/auth/login": {
+            "post": {
+                "description": "login",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "delete chat group",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Chat"
+                    "Auth"
                 ],
-                "summary": "DeleteChatGroup",
-                "operationId": "deleteChatGroup",
+                "summary": "Login",
+                "operationId": "login",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "chat id",
-                        "name": "chatId",
-                        "in": "path",
-                        "required": true
+                        "description": "credentials",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.AuthLogin"
+                        }
                     }
                 ],
                 "responses": {
@@ -312,6 +323,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/rest.errorResponse"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -321,36 +338,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/chat/group/admin/update": {
-            "put": {
+        "/api/auth/logout": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "transfer chat group admin",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "end the current session, its refresh token and access token stop working right away",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Chat"
-                ],
-                "summary": "TransferChatGroupAdmin",
-                "operationId": "transferChatGroupAdmin",
-                "parameters": [
-                    {
-                        "description": "update chat group admin",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/core.UpdateGroupChatAdminReq"
-                        }
-                    }
+                    "Auth"
                 ],
+                "summary": "Logout",
+                "operationId": "logout",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -361,6 +364,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/rest.errorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -370,36 +379,22 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/chat/group/create": {
+        "/api/auth/logout/all": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "create chat group",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "end every session of the user, all refresh and access tokens issued so far stop working right away",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Chat"
-                ],
-                "summary": "CreateChatGroup",
-                "operationId": "createChatGroup",
-                "parameters": [
-                    {
-                        "description": "create chat group",
-                        "name": "input",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/core.CreateChatGroupReq"
-                        }
-                    }
+                    "Auth"
                 ],
+                "summary": "LogoutAll",
+                "operationId": "logoutAll",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -410,6 +405,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/rest.errorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -419,34 +420,26 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/chat/group/join/{chatId}": {
+        "/api/auth/refresh": {
             "post": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
+                "description": "refresh",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "join chat",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Chat"
-                ],
-                "summary": "JoinChat",
-                "operationId": "joinChat",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "chat id",
-                        "name": "chatId",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Auth"
                 ],
+                "summary": "Refresh",
+                "operationId": "refresh",
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.tokenResponse"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -454,6 +447,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/rest.errorResponse"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -463,29 +462,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/chat/group/leave/{chatId}": {
-            "delete": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
+        "/api/auth/register": {
+            "post": {
+                "description": "register",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "leave chat group",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Chat"
+                    "Auth"
                 ],
-                "summary": "LeaveChatGroup",
-                "operationId": "leaveChatGroup",
-                "parameters": [
+                "summary": "Register",
+                "operationId": "register",
+                "parameters": [
                     {
-                        "type": "string",
-                        "description": "chat id",
-                        "name": "chatId",
-                        "in": "path",
-                        "required": true
+                        "description": "credentials",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.AuthRegister"
+                        }
                     }
                 ],
                 "responses": {
@@ -507,38 +506,62 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/chat/message/get/{chatId}": {
-            "get": {
-                "security": [
-                    {
-                        "ApiKeyAuth": []
-                    }
+        "/api/auth/verify": {
+            "post": {
+                "description": "verify",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "get messages from chat",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Chat"
+                    "Auth"
                 ],
-                "summary": "GetMessages",
-                "operationId": "getMessages",
+                "summary": "Verify",
+                "operationId": "verify",
                 "parameters": [
+                    {
+                        "description": "phone and code",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.AuthVerify"
+                        }
+                    },
                     {
                         "type": "string",
-                        "description": "chat id",
-                        "name": "roomId",
-                        "in": "path",
-                        "required": true
+                        "description": "device name shown in the session list",
+                        "name": "X-Device-Name",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "device platform shown in the session list",
+                        "name": "X-Device-Platform",
+                        "in": "header"
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.tokenResponse"
+                        }
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/rest.errorResponse"
                         }
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -548,14 +571,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/chat/message/send": {
+        "/api/blocks/block": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "send message",
+                "description": "block user",
                 "consumes": [
                     "application/json"
                 ],
@@ -563,28 +586,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Chat"
+                    "Blocks"
                 ],
-                "summary": "SendMessage",
-                "operationId": "sendMessage",
+                "summary": "BlockUser",
+                "operationId": "blockUser",
                 "parameters": [
                     {
-                        "description": "message",
-                        "name": "message",
+                        "description": "user to block",
+                        "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/core.SendMessageReq"
+                            "$ref": "#/definitions/core.BlockUserReq"
                         }
                     }
                 ],
                 "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/rest.errorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -594,29 +626,29 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/chat/wall": {
+        "/api/blocks/get": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "get chat wall",
+                "description": "get blocked users, recently blocked first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Chat"
+                    "Blocks"
                 ],
-                "summary": "ChatWall",
-                "operationId": "chatWall",
+                "summary": "GetBlockedUsers",
+                "operationId": "getBlockedUsers",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/core.WallChatResp"
+                                "$ref": "#/definitions/core.BlockedUserResp"
                             }
                         }
                     },
@@ -635,36 +667,86 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/profile/": {
-            "get": {
+        "/api/blocks/unblock/{userId}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "get profile",
+                "description": "unblock user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Profile"
+                    "Blocks"
                 ],
-                "summary": "GetProfile",
-                "operationId": "getProfile",
+                "summary": "UnblockUser",
+                "operationId": "unblockUser",
                 "parameters": [
                     {
-                        "description": "profile",
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/channel/create": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "create broadcast channel",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Channel"
+                ],
+                "summary": "CreateChannel",
+                "operationId": "createChannel",
+                "parameters": [
+                    {
+                        "description": "create channel",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/core.GetProfileResp"
+                            "$ref": "#/definitions/core.CreateChannelReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatIDResp"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -681,34 +763,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/profile/avatar/delete/{avatarId}": {
-            "delete": {
+        "/api/chat/channel/join": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "delete avatar by avatar id",
+                "description": "subscribe to channel by handle",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Profile"
+                    "Channel"
                 ],
-                "summary": "DeleteAvatar",
-                "operationId": "deleteAvatar",
+                "summary": "JoinChannel",
+                "operationId": "joinChannel",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "avatarId",
-                        "name": "avatarId",
-                        "in": "path",
-                        "required": true
+                        "description": "channel handle",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.JoinChannelReq"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatIDResp"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -725,28 +815,28 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/profile/avatar/upload": {
-            "post": {
+        "/api/chat/channel/leave/{chatId}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "upload avatar",
+                "description": "unsubscribe from channel",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Profile"
+                    "Channel"
                 ],
-                "summary": "UploadAvatar",
-                "operationId": "uploadAvatar",
+                "summary": "LeaveChannel",
+                "operationId": "leaveChannel",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "avatar",
-                        "name": "avatar",
-                        "in": "formData",
+                        "type": "string",
+                        "description": "chat id",
+                        "name": "chatId",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -769,30 +859,36 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/profile/avatars": {
+        "/api/chat/channel/subscribers/{chatId}": {
             "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "get all avatars",
+                "description": "get channel subscriber count",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Profile"
+                    "Channel"
+                ],
+                "summary": "ChannelSubscribers",
+                "operationId": "channelSubscribers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "chat id",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "GetAvatars",
-                "operationId": "getAvatars",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/core.GetAllUserAvatarsResp"
-                            }
+                            "$ref": "#/definitions/core.ChannelSubscribersResp"
                         }
                     },
                     "400": {
@@ -810,14 +906,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/profile/update": {
-            "patch": {
+        "/api/chat/default/create": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "update profile",
+                "description": "get or create the direct chat with a user found by phone or username",
                 "consumes": [
                     "application/json"
                 ],
@@ -825,24 +921,27 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Profile"
+                    "Chat"
                 ],
-                "summary": "UpdateProfile",
-                "operationId": "updateProfile",
+                "summary": "CreateChatDefault",
+                "operationId": "createChatDefault",
                 "parameters": [
                     {
-                        "description": "user data to update",
+                        "description": "create chat default",
                         "name": "input",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/core.UpdateUserReq"
+                            "$ref": "#/definitions/core.CreateDefaultChatReq"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatIDResp"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -859,19 +958,31 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/stream/connect": {
-            "get": {
+        "/api/chat/default/delete/{chatId}": {
+            "delete": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "connect to a streaming session",
+                "description": "delete chat default",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Stream"
+                    "Chat"
+                ],
+                "summary": "DeleteChatDefault",
+                "operationId": "deleteChatDefault",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "chat id",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Connect",
-                "operationId": "streamConnect",
                 "responses": {
                     "200": {
                         "description": "OK"
@@ -891,22 +1002,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/stream/disconnect": {
+        "/api/chat/folder/create": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "disconnect from the streaming session",
+                "description": "create chat folder",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "Stream"
+                    "Chat"
+                ],
+                "summary": "CreateChatFolder",
+                "operationId": "createChatFolder",
+                "parameters": [
+                    {
+                        "description": "folder",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatFolderReq"
+                        }
+                    }
                 ],
-                "summary": "Disconnect",
-                "operationId": "streamDisconnect",
                 "responses": {
-                    "200": {
-                        "description": "OK"
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatFolderResp"
+                        }
                     },
                     "400": {
                         "description": "Bad Request",
@@ -914,106 +1045,3502 @@ const docTemplate = `{
                             "$ref": "#/definitions/rest.errorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/rest.errorResponse"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/folder/delete/{folderId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "delete chat folder, chats stay on the wall",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "DeleteChatFolder",
+                "operationId": "deleteChatFolder",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "folder id",
+                        "name": "folderId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/folder/get": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get chat folders in their order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "GetChatFolders",
+                "operationId": "getChatFolders",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.ChatFolderResp"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/folder/reorder": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "reorder chat folders",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "ReorderChatFolders",
+                "operationId": "reorderChatFolders",
+                "parameters": [
+                    {
+                        "description": "folder ids in the new order",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.ReorderChatFoldersReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/folder/update": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "update chat folder name, chats and rules",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "UpdateChatFolder",
+                "operationId": "updateChatFolder",
+                "parameters": [
+                    {
+                        "description": "folder",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.UpdateChatFolderReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatFolderResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/group/add": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "add another user to a group",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "AddChatGroupMember",
+                "operationId": "addChatGroupMember",
+                "parameters": [
+                    {
+                        "description": "group and user",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.AddChatGroupMemberReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/group/admin/avatar/delete/{chatId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "delete group or channel avatar",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "DeleteChatAvatar",
+                "operationId": "deleteChatAvatar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "chat id",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatInfoResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/group/admin/avatar/upload/{chatId}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "upload group or channel avatar",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "UploadChatAvatar",
+                "operationId": "uploadChatAvatar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "chat id",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "avatar",
+                        "name": "avatar",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatInfoResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/group/admin/delete/{chatId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "delete chat group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "DeleteChatGroup",
+                "operationId": "deleteChatGroup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "chat id",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/group/admin/leave": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "leave group as admin, ownership goes to the successor or to the longest-standing member",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "LeaveChatGroupAdmin",
+                "operationId": "leaveChatGroupAdmin",
+                "parameters": [
+                    {
+                        "description": "leave chat group as admin",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.LeaveChatGroupAdminReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatAdminLeftResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/group/admin/requests/approve": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "approve pending join request",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "ApproveJoinRequest",
+                "operationId": "approveJoinRequest",
+                "parameters": [
+                    {
+                        "description": "join request",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.JoinRequestDecisionReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/group/admin/requests/decline": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "decline pending join request",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "DeclineJoinRequest",
+                "operationId": "declineJoinRequest",
+                "parameters": [
+                    {
+                        "description": "join request",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.JoinRequestDecisionReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/group/admin/requests/get/{chatId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get pending join requests of a group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "GetJoinRequests",
+                "operationId": "getJoinRequests",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "chat id",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.JoinRequestResp"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/group/admin/topic/create": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "create topic in a group in topics mode",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "CreateChatTopic",
+                "operationId": "createChatTopic",
+                "parameters": [
+                    {
+                        "description": "topic",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.CreateChatTopicReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatTopicResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/group/admin/update": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "transfer chat group admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "TransferChatGroupAdmin",
+                "operationId": "transferChatGroupAdmin",
+                "parameters": [
+                    {
+                        "description": "update chat group admin",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.UpdateGroupChatAdminReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/group/admin/update/description": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "update chat group description",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "UpdateChatGroupDescription",
+                "operationId": "updateChatGroupDescription",
+                "parameters": [
+                    {
+                        "description": "update chat group description",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.UpdateGroupChatDescriptionReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatInfoResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/group/admin/update/settings": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "update group or channel settings",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "UpdateChatGroupSettings",
+                "operationId": "updateChatGroupSettings",
+                "parameters": [
+                    {
+                        "description": "chat settings",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.UpdateGroupChatSettingsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatInfoResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/group/create": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "create chat group",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "CreateChatGroup",
+                "operationId": "createChatGroup",
+                "parameters": [
+                    {
+                        "description": "create chat group",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.CreateChatGroupReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/group/join/{chatId}": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "join chat",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "JoinChat",
+                "operationId": "joinChat",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "chat id",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/group/leave/{chatId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "leave chat group",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "LeaveChatGroup",
+                "operationId": "leaveChatGroup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "chat id",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/message/forward": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "forward message to another chat, e.g. to saved messages",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "ForwardMessage",
+                "operationId": "forwardMessage",
+                "parameters": [
+                    {
+                        "description": "message to forward",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.ForwardMessageReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/message/get/{chatId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get messages from chat",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "GetMessages",
+                "operationId": "getMessages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "chat id",
+                        "name": "roomId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "list only messages of the topic",
+                        "name": "topic_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/message/read": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "mark messages of the chat as read up to the given message",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "ReadMessages",
+                "operationId": "readMessages",
+                "parameters": [
+                    {
+                        "description": "last read message",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.ReadMessagesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/message/search/{chatId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "search messages of a chat, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "SearchMessages",
+                "operationId": "searchMessages",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "chat id",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "text to search",
+                        "name": "text",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "search only in the topic",
+                        "name": "topic_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.ChatMessage"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/message/send": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "send message",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "SendMessage",
+                "operationId": "sendMessage",
+                "parameters": [
+                    {
+                        "description": "message",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.SendMessageReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/prefs/pins/reorder": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "reorder pinned chats on the wall",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "ReorderPinnedChats",
+                "operationId": "reorderPinnedChats",
+                "parameters": [
+                    {
+                        "description": "pinned chat ids in the new order",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.ReorderPinnedChatsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/prefs/update": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "archive, pin or mute chat for the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "UpdateChatPrefs",
+                "operationId": "updateChatPrefs",
+                "parameters": [
+                    {
+                        "description": "chat preferences",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.UpdateChatPrefsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/saved": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get or create the saved messages chat",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "SavedChat",
+                "operationId": "savedChat",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatIDResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/topic/get/{chatId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get topics of a group with last messages and unread counts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "GetChatTopics",
+                "operationId": "getChatTopics",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "chat id",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.ChatTopicResp"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/wall": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get chat wall, pinned chats go first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "ChatWall",
+                "operationId": "chatWall",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "list archived chats instead",
+                        "name": "archived",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "list only chats of the folder",
+                        "name": "folder_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.WallChatResp"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/chat/{chatId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get chat info",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Chat"
+                ],
+                "summary": "ChatInfo",
+                "operationId": "chatInfo",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "chat id",
+                        "name": "chatId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ChatInfoResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/contacts/delete/{userId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "delete contact",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contacts"
+                ],
+                "summary": "DeleteContact",
+                "operationId": "deleteContact",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "contact user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/contacts/get": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get contacts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contacts"
+                ],
+                "summary": "GetContacts",
+                "operationId": "getContacts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.ContactResp"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/contacts/salt": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get the salt for hashing phone numbers before sync",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contacts"
+                ],
+                "summary": "ContactsSalt",
+                "operationId": "contactsSalt",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ContactsSaltResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/contacts/sync": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "upload address book as phone numbers or salted hashes, registered users are added to contacts",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contacts"
+                ],
+                "summary": "SyncContacts",
+                "operationId": "syncContacts",
+                "parameters": [
+                    {
+                        "description": "address book",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.SyncContactsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.ContactResp"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/contacts/update": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "set custom contact name, empty name resets it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Contacts"
+                ],
+                "summary": "UpdateContact",
+                "operationId": "updateContact",
+                "parameters": [
+                    {
+                        "description": "contact name",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.UpdateContactReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.ContactResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/presence/get": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get online status and last seen time of users, hidden by their privacy settings are shown offline",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Presence"
+                ],
+                "summary": "GetPresence",
+                "operationId": "getPresence",
+                "parameters": [
+                    {
+                        "description": "user ids",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.PresenceReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.PresenceResp"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get profile",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "GetProfile",
+                "operationId": "getProfile",
+                "parameters": [
+                    {
+                        "description": "profile",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.GetProfileResp"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/avatar/current": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "choose the avatar shown on the profile",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "SetCurrentAvatar",
+                "operationId": "setCurrentAvatar",
+                "parameters": [
+                    {
+                        "description": "avatar id",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.SetCurrentAvatarReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/avatar/delete/{avatarId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "delete avatar by avatar id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "DeleteAvatar",
+                "operationId": "deleteAvatar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "avatarId",
+                        "name": "avatarId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/avatar/reorder": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "reorder the avatar gallery, the list must contain every avatar of the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "ReorderAvatars",
+                "operationId": "reorderAvatars",
+                "parameters": [
+                    {
+                        "description": "avatar ids in the new order",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.ReorderAvatarsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/avatar/upload": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "upload avatar, the image is cropped to a square and stored in 64, 256 and 640 pixel sizes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "UploadAvatar",
+                "operationId": "uploadAvatar",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "avatar",
+                        "name": "avatar",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/avatars": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get all avatars",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "GetAvatars",
+                "operationId": "getAvatars",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.GetAllUserAvatarsResp"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/details": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "update display name, bio and status, only the fields that are set change and an empty string clears the field",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "UpdateProfileDetails",
+                "operationId": "updateProfileDetails",
+                "parameters": [
+                    {
+                        "description": "profile details to update",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.UpdateProfileDetailsReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/privacy/get": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get privacy settings",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "GetPrivacy",
+                "operationId": "getPrivacy",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.PrivacySettingResp"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/privacy/update": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "update privacy setting, exception lists replace the previous ones",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "UpdatePrivacy",
+                "operationId": "updatePrivacy",
+                "parameters": [
+                    {
+                        "description": "privacy setting",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.UpdatePrivacyReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.PrivacySettingResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/search": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "search users by username prefix",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "SearchUsers",
+                "operationId": "searchUsers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "username prefix",
+                        "name": "query",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "max results, 20 by default",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.UserSearchResp"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/update": {
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "update profile",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "UpdateProfile",
+                "operationId": "updateProfile",
+                "parameters": [
+                    {
+                        "description": "user data to update",
+                        "name": "input",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/core.UpdateUserReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/username/{username}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get profile of another user by username",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "GetUserProfileByUsername",
+                "operationId": "getUserProfileByUsername",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.UserProfileResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/profile/{userId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get profile of another user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Profile"
+                ],
+                "summary": "GetUserProfile",
+                "operationId": "getUserProfile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/core.UserProfileResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sessions/get": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get active sessions of the user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "GetSessions",
+                "operationId": "getSessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/core.SessionResp"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sessions/revoke-others": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "log out every session but the current one",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "RevokeOtherSessions",
+                "operationId": "revokeOtherSessions",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/sessions/revoke/{sessionId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "log out the session and close its stream",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "RevokeSession",
+                "operationId": "revokeSession",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "session id",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stream/connect": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "connect to a streaming session",
+                "tags": [
+                    "Stream"
+                ],
+                "summary": "Connect",
+                "operationId": "streamConnect",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stream/disconnect": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "disconnect from the streaming session",
+                "tags": [
+                    "Stream"
+                ],
+                "summary": "Disconnect",
+                "operationId": "streamDisconnect",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/rest.errorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "core.AccountDeletionResp": {
+            "type": "object",
+            "properties": {
+                "deletion_scheduled_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.AddChatGroupMemberReq": {
+            "type": "object",
+            "required": [
+                "chat_id",
+                "user_id"
+            ],
+            "properties": {
+                "chat_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.AuthLogin": {
+            "type": "object",
+            "required": [
+                "phone"
+            ],
+            "properties": {
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.AuthRegister": {
+            "type": "object",
+            "required": [
+                "phone",
+                "username"
+            ],
+            "properties": {
+                "phone": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string",
+                    "minLength": 2
+                }
+            }
+        },
+        "core.AuthVerify": {
+            "type": "object",
+            "required": [
+                "code",
+                "phone"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.BlockUserReq": {
+            "type": "object",
+            "required": [
+                "user_id"
+            ],
+            "properties": {
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.BlockedUserResp": {
+            "type": "object",
+            "properties": {
+                "blocked_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.ChangePhoneReq": {
+            "type": "object",
+            "required": [
+                "phone"
+            ],
+            "properties": {
+                "phone": {
+                    "type": "string"
+                },
+                "verify_old": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "core.ChannelSubscribersResp": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "integer"
+                },
+                "subscribers_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.ChatAdminLeftResp": {
+            "type": "object",
+            "properties": {
+                "chat_deleted": {
+                    "type": "boolean"
+                },
+                "chat_id": {
+                    "type": "integer"
+                },
+                "former_admin_id": {
+                    "type": "integer"
+                },
+                "new_admin_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.ChatFolderReq": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "chat_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "exclude_muted": {
+                    "type": "boolean"
+                },
+                "include_channels": {
+                    "type": "boolean"
+                },
+                "include_direct": {
+                    "type": "boolean"
+                },
+                "include_groups": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "unread_only": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "core.ChatFolderResp": {
+            "type": "object",
+            "properties": {
+                "chat_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "exclude_muted": {
+                    "type": "boolean"
+                },
+                "folder_id": {
+                    "type": "integer"
+                },
+                "include_channels": {
+                    "type": "boolean"
+                },
+                "include_direct": {
+                    "type": "boolean"
+                },
+                "include_groups": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "unread_only": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "core.ChatIDResp": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.ChatInfoResp": {
+            "type": "object",
+            "properties": {
+                "admin_id": {
+                    "type": "integer"
+                },
+                "avatar_url": {
+                    "type": "string"
+                },
+                "chat_id": {
+                    "type": "integer"
+                },
+                "chat_name": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "join_approval": {
+                    "type": "boolean"
+                },
+                "members_count": {
+                    "type": "integer"
+                },
+                "only_admins_post": {
+                    "type": "boolean"
+                },
+                "signed_posts": {
+                    "type": "boolean"
+                },
+                "slow_mode_seconds": {
+                    "type": "integer"
+                },
+                "topics_enabled": {
+                    "type": "boolean"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.ChatMessage": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "integer"
+                },
+                "chat_message_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "forwarded_from": {
+                    "type": "string"
+                },
+                "signature": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                },
+                "topic_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.ChatTopicResp": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "last_message": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "topic_id": {
+                    "type": "integer"
+                },
+                "unread_count": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.ConfirmAccountDeletionReq": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.ConfirmPhoneChangeReq": {
+            "type": "object",
+            "required": [
+                "new_code"
+            ],
+            "properties": {
+                "new_code": {
+                    "type": "string"
+                },
+                "old_code": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.ContactResp": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "phone_hash": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.ContactsSaltResp": {
+            "type": "object",
+            "properties": {
+                "salt": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.CreateChannelReq": {
+            "type": "object",
+            "required": [
+                "chat_name",
+                "handle"
+            ],
+            "properties": {
+                "chat_name": {
+                    "type": "string"
+                },
+                "handle": {
+                    "type": "string"
+                },
+                "signed_posts": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "core.CreateChatGroupReq": {
+            "type": "object",
+            "required": [
+                "chat_name"
+            ],
+            "properties": {
+                "chat_name": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.CreateChatTopicReq": {
+            "type": "object",
+            "required": [
+                "chat_id",
+                "name"
+            ],
+            "properties": {
+                "chat_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
+                }
+            }
+        },
+        "core.CreateDefaultChatReq": {
+            "type": "object",
+            "properties": {
+                "phone": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.ForwardMessageReq": {
+            "type": "object",
+            "required": [
+                "chat_id",
+                "message_id"
+            ],
+            "properties": {
+                "chat_id": {
+                    "type": "integer"
+                },
+                "message_id": {
+                    "type": "integer"
+                },
+                "topic_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.GetAllUserAvatarsResp": {
+            "type": "object",
+            "properties": {
+                "avatar_id": {
+                    "type": "integer"
+                },
+                "avatar_url": {
+                    "type": "string"
+                },
+                "avatar_urls": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "current": {
+                    "type": "boolean"
+                },
+                "position": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.GetProfileResp": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "avatar_urls": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
                     }
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "deletion_scheduled_at": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone": {
+                    "type": "string"
+                },
+                "status_expires_at": {
+                    "type": "string"
+                },
+                "status_text": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.JoinChannelReq": {
+            "type": "object",
+            "required": [
+                "handle"
+            ],
+            "properties": {
+                "handle": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.JoinRequestDecisionReq": {
+            "type": "object",
+            "required": [
+                "request_id"
+            ],
+            "properties": {
+                "request_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.JoinRequestResp": {
+            "type": "object",
+            "properties": {
+                "chat_id": {
+                    "type": "integer"
+                },
+                "chat_name": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                },
+                "username": {
+                    "type": "string"
                 }
             }
-        }
-    },
-    "definitions": {
-        "core.AuthLogin": {
+        },
+        "core.LeaveChatGroupAdminReq": {
             "type": "object",
             "required": [
-                "phone"
+                "chat_id"
             ],
+            "properties": {
+                "chat_id": {
+                    "type": "integer"
+                },
+                "successor_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.PhoneChangedResp": {
+            "type": "object",
             "properties": {
                 "phone": {
                     "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "core.AuthRegister": {
+        "core.PresenceReq": {
             "type": "object",
             "required": [
-                "phone",
-                "username"
+                "user_ids"
             ],
             "properties": {
-                "phone": {
+                "user_ids": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "core.PresenceResp": {
+            "type": "object",
+            "properties": {
+                "last_seen": {
                     "type": "string"
                 },
-                "username": {
-                    "type": "string",
-                    "minLength": 2
+                "online": {
+                    "type": "boolean"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "core.CreateChatGroupReq": {
+        "core.PrivacySettingResp": {
+            "type": "object",
+            "properties": {
+                "always_allow": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "level": {
+                    "type": "string"
+                },
+                "never_allow": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "setting": {
+                    "type": "string"
+                }
+            }
+        },
+        "core.ReadMessagesReq": {
             "type": "object",
             "required": [
-                "chat_name"
+                "chat_id",
+                "message_id"
             ],
             "properties": {
-                "chat_name": {
-                    "type": "string"
+                "chat_id": {
+                    "type": "integer"
+                },
+                "message_id": {
+                    "type": "integer"
                 }
             }
         },
-        "core.CreateDefaultChatReq": {
+        "core.ReorderAvatarsReq": {
             "type": "object",
             "required": [
-                "phone"
+                "avatar_ids"
             ],
             "properties": {
-                "phone": {
-                    "type": "string"
+                "avatar_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
                 }
             }
         },
-        "core.GetAllUserAvatarsResp": {
+        "core.ReorderChatFoldersReq": {
             "type": "object",
+            "required": [
+                "folder_ids"
+            ],
             "properties": {
-                "avatar_id": {
+                "folder_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "core.ReorderPinnedChatsReq": {
+            "type": "object",
+            "required": [
+                "chat_ids"
+            ],
+            "properties": {
+                "chat_ids": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "core.SendMessageReq": {
+            "type": "object",
+            "required": [
+                "chat_id",
+                "text"
+            ],
+            "properties": {
+                "chat_id": {
                     "type": "integer"
                 },
-                "avatar_url": {
+                "text": {
                     "type": "string"
+                },
+                "topic_id": {
+                    "type": "integer"
                 }
             }
         },
-        "core.GetProfileResp": {
+        "core.SessionResp": {
             "type": "object",
             "properties": {
-                "avatar_url": {
+                "created_at": {
                     "type": "string"
                 },
-                "name": {
+                "current": {
+                    "type": "boolean"
+                },
+                "device_name": {
                     "type": "string"
                 },
-                "phone": {
+                "ip": {
                     "type": "string"
                 },
-                "user_id": {
+                "last_used_at": {
+                    "type": "string"
+                },
+                "platform": {
+                    "type": "string"
+                },
+                "session_id": {
                     "type": "integer"
+                },
+                "user_agent": {
+                    "type": "string"
                 }
             }
         },
-        "core.SendMessageReq": {
+        "core.SetCurrentAvatarReq": {
             "type": "object",
             "required": [
-                "chat_id",
-                "text"
+                "avatar_id"
+            ],
+            "properties": {
+                "avatar_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.SyncContactsReq": {
+            "type": "object",
+            "properties": {
+                "hashes": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "phones": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "core.UpdateChatFolderReq": {
+            "type": "object",
+            "required": [
+                "folder_id",
+                "name"
             ],
             "properties": {
+                "chat_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "exclude_muted": {
+                    "type": "boolean"
+                },
+                "folder_id": {
+                    "type": "integer"
+                },
+                "include_channels": {
+                    "type": "boolean"
+                },
+                "include_direct": {
+                    "type": "boolean"
+                },
+                "include_groups": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 32
+                },
+                "unread_only": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "core.UpdateChatPrefsReq": {
+            "type": "object",
+            "required": [
+                "chat_id"
+            ],
+            "properties": {
+                "archived": {
+                    "type": "boolean"
+                },
                 "chat_id": {
                     "type": "integer"
                 },
-                "text": {
+                "muted_until": {
                     "type": "string"
+                },
+                "pinned": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "core.UpdateContactReq": {
+            "type": "object",
+            "required": [
+                "contact_user_id"
+            ],
+            "properties": {
+                "contact_user_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 64
                 }
             }
         },
@@ -1032,6 +4559,21 @@ const docTemplate = `{
                 }
             }
         },
+        "core.UpdateGroupChatDescriptionReq": {
+            "type": "object",
+            "required": [
+                "chat_id"
+            ],
+            "properties": {
+                "chat_id": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string",
+                    "maxLength": 255
+                }
+            }
+        },
         "core.UpdateGroupChatNameReq": {
             "type": "object",
             "required": [
@@ -1047,25 +4589,161 @@ const docTemplate = `{
                 }
             }
         },
+        "core.UpdateGroupChatSettingsReq": {
+            "type": "object",
+            "required": [
+                "chat_id"
+            ],
+            "properties": {
+                "chat_id": {
+                    "type": "integer"
+                },
+                "join_approval": {
+                    "type": "boolean"
+                },
+                "only_admins_post": {
+                    "type": "boolean"
+                },
+                "signed_posts": {
+                    "type": "boolean"
+                },
+                "slow_mode_seconds": {
+                    "type": "integer",
+                    "maximum": 86400,
+                    "minimum": 0
+                },
+                "topics_enabled": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "core.UpdatePrivacyReq": {
+            "type": "object",
+            "required": [
+                "level",
+                "setting"
+            ],
+            "properties": {
+                "always_allow": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "level": {
+                    "type": "string",
+                    "enum": [
+                        "everybody",
+                        "contacts",
+                        "nobody"
+                    ]
+                },
+                "never_allow": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "setting": {
+                    "type": "string",
+                    "enum": [
+                        "phone",
+                        "last_seen",
+                        "avatar",
+                        "group_add"
+                    ]
+                }
+            }
+        },
+        "core.UpdateProfileDetailsReq": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string",
+                    "maxLength": 140
+                },
+                "display_name": {
+                    "type": "string",
+                    "maxLength": 64
+                },
+                "status_expires_at": {
+                    "type": "string"
+                },
+                "status_text": {
+                    "type": "string",
+                    "maxLength": 70
+                }
+            }
+        },
         "core.UpdateUserReq": {
             "type": "object",
             "required": [
-                "name",
-                "phone"
+                "name"
             ],
             "properties": {
                 "name": {
                     "type": "string",
                     "minLength": 2
+                }
+            }
+        },
+        "core.UserProfileResp": {
+            "type": "object",
+            "properties": {
+                "avatar_url": {
+                    "type": "string"
+                },
+                "avatar_urls": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "display_name": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
                 },
                 "phone": {
                     "type": "string"
+                },
+                "status_expires_at": {
+                    "type": "string"
+                },
+                "status_text": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "core.UserSearchResp": {
+            "type": "object",
+            "properties": {
+                "display_name": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
         "core.WallChatResp": {
             "type": "object",
             "properties": {
+                "archived": {
+                    "type": "boolean"
+                },
                 "chat_id": {
                     "type": "integer"
                 },
@@ -1074,6 +4752,25 @@ const docTemplate = `{
                 },
                 "chat_name": {
                     "type": "string"
+                },
+                "muted": {
+                    "type": "boolean"
+                },
+                "muted_until": {
+                    "type": "string"
+                },
+                "pinned": {
+                    "type": "boolean"
+                },
+                "topics": {
+                    "description": "Topics is set for groups in topics mode.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/core.ChatTopicResp"
+                    }
+                },
+                "unread_count": {
+                    "type": "integer"
                 }
             }
         },
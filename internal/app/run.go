@@ -75,7 +75,10 @@ func Run() {
 	presignS3 := s3.NewPresignClient(storageS3)
 
 	// init db
-	db, err := gorm.Open(postgres.Open(cfg.Database.Dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(cfg.Database.Dsn), &gorm.Config{
+		// Unique violations come back as gorm.ErrDuplicatedKey.
+		TranslateError: true,
+	})
 	if err != nil {
 		log.Error("Error when connecting to the database: ", err)
 		panic(err)
@@ -87,8 +90,18 @@ func Run() {
 		panic(err)
 	}
 
-	// init token manager
-	manager, err := token.NewManager(cfg.JWT.Secret)
+	// init token manager, every .pem file of the keys dir verifies access
+	// tokens and the active one signs them
+	var jwtKeys []*token.Key
+	if cfg.JWT.KeysDir != "" {
+		jwtKeys, err = token.LoadKeys(cfg.JWT.KeysDir)
+		if err != nil {
+			log.Error("Error when loading jwt keys: ", err)
+			panic(err)
+		}
+	}
+
+	manager, err := token.NewManager(jwtKeys, cfg.JWT.ActiveKeyID, cfg.JWT.Secret, cfg.JWT.LegacyUntil)
 	if err != nil {
 		log.Error("Error when creating token manager: ", err)
 		panic(err)
@@ -114,25 +127,58 @@ func Run() {
 		Password: cfg.Twilio.AuthKey,
 	})
 
+	// hash phones of users registered before contacts
+	contactsService := service.NewContacts(psql.NewContacts(db, log), cfg.Contacts.PhoneSalt, log)
+	if err := contactsService.BackfillPhoneHashes(appCtx); err != nil {
+		log.Error("Error when hashing user phones: ", err)
+		panic(err)
+	}
+
+	// purge accounts whose deletion grace period is over
+	accountService := service.NewAccount(psql.NewAccount(db, log),
+		rdb.NewAccount(rdbClient, cfg.Verify.TTL, log),
+		repoS3.NewProfile(storageS3, presignS3, cfg.S3.BucketName, log),
+		repoS3.NewChat(storageS3, presignS3, cfg.S3.BucketName, log),
+		twilio.NewVerify(twilioClient, cfg.Twilio.Phone, cfg.Twilio.SID, log),
+		cfg.S3.AvatarKeySalt, cfg.Contacts.PhoneSalt, log)
+	go accountService.RunPurge(appCtx, service.ACCOUNT_PURGE_INTERVAL)
+
+	trustedProxies, err := rest.ParseTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		log.Error("Error when parsing trusted proxies: ", err)
+		panic(err)
+	}
+
 	// init dependencies
 	handler := rest.NewHandler(rest.Deps{
 		Auth: service.NewAuth(psql.NewAuth(db, log),
 			rdb.NewVerife(rdbClient, cfg.Verify.TTL, log),
+			rdb.NewAuth(rdbClient, log),
 			twilio.NewVerify(twilioClient, cfg.Twilio.Phone, cfg.Twilio.SID, log),
-			manager, cfg.JWT.AccessTokenTTL, cfg.JWT.RefreshTokenTTL, log),
+			manager, cfg.JWT.AccessTokenTTL, cfg.JWT.RefreshTokenTTL, cfg.Contacts.PhoneSalt, log),
 		Profile: service.NewProfile(psql.NewProfile(db, log),
+			rdb.NewProfile(rdbClient, log),
 			repoS3.NewProfile(storageS3, presignS3, cfg.S3.BucketName, log),
 			cfg.S3.AvatarKeySalt, log),
 		WebSocket: service.NewWebSocket(psql.NewWebSocket(db, log),
-			log),
+			rdb.NewChat(rdbClient, log),
+			repoS3.NewChat(storageS3, presignS3, cfg.S3.BucketName, log),
+			cfg.S3.AvatarKeySalt, log),
+		Contacts: contactsService,
+		Account:  accountService,
 
 		Encoder: encoder.New(cfg.Server.EncodeSecret),
 
 		WebSocketHandler: websocket.NewWebSocketHandler(encoder.New(cfg.Server.EncodeSecret)),
 
+		TrustedProxies: trustedProxies,
+
 		Log: log,
 	})
 
+	// take offline the users whose streams died with their instance
+	go handler.RunPresenceExpiry(appCtx)
+
 	// init api
 	api := transport.NewApi(handler, cfg.Server.SwagAddr)
 
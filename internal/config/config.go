@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -22,6 +23,7 @@ type Config struct {
 	Twilio   Twilio
 	Verify   Verify
 	JWT      JWT
+	Contacts Contacts
 }
 
 type Server struct {
@@ -29,6 +31,9 @@ type Server struct {
 	SwagAddr     string
 	AWSRegion    string
 	EncodeSecret string
+	// TrustedProxies are the addresses or CIDRs of the proxies whose
+	// X-Forwarded-For header is believed.
+	TrustedProxies []string
 }
 
 type Database struct {
@@ -55,8 +60,15 @@ type Verify struct {
 	TTL time.Duration `mapstructure:"ttl"`
 }
 
+type Contacts struct {
+	PhoneSalt string
+}
+
 type JWT struct {
 	Secret          string
+	KeysDir         string
+	ActiveKeyID     string
+	LegacyUntil     time.Time
 	AccessTokenTTL  time.Duration `mapstructure:"access_ttl"`
 	RefreshTokenTTL time.Duration `mapstructure:"refresh_ttl"`
 }
@@ -91,8 +103,23 @@ func setEnv(cfg *Config) (*Config, error) {
 	cfg.Server.SwagAddr = os.Getenv("SERVER_SWAG_ADDR")
 	cfg.Server.AWSRegion = os.Getenv("AWS_DEFAULT_REGION")
 	cfg.Server.EncodeSecret = os.Getenv("SERVER_ENCODE_SECRET")
+	for _, proxy := range strings.Split(os.Getenv("SERVER_TRUSTED_PROXIES"), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			cfg.Server.TrustedProxies = append(cfg.Server.TrustedProxies, proxy)
+		}
+	}
 
 	cfg.JWT.Secret = os.Getenv("JWT_SECRET")
+	cfg.JWT.KeysDir = os.Getenv("JWT_KEYS_DIR")
+	cfg.JWT.ActiveKeyID = os.Getenv("JWT_ACTIVE_KEY_ID")
+	if legacyUntil := os.Getenv("JWT_LEGACY_UNTIL"); legacyUntil != "" {
+		parsed, err := time.Parse(time.RFC3339, legacyUntil)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_LEGACY_UNTIL: %w", err)
+		}
+
+		cfg.JWT.LegacyUntil = parsed
+	}
 
 	cfg.RDB.Addr = os.Getenv("REDIS_ADDR")
 
@@ -103,6 +130,8 @@ func setEnv(cfg *Config) (*Config, error) {
 	cfg.S3.BucketName = os.Getenv("S3_BUCKET_NAME")
 	cfg.S3.AvatarKeySalt = os.Getenv("S3_AVATAR_SALT")
 
+	cfg.Contacts.PhoneSalt = os.Getenv("CONTACTS_PHONE_SALT")
+
 	postgresConn := generatDsn()
 
 	cfg.Database.Url = postgresConn[0]
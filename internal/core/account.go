@@ -0,0 +1,53 @@
+package core
+
+// DeletedAccountName replaces the username on messages of deleted accounts.
+const DeletedAccountName = "Deleted Account"
+
+type ConfirmAccountDeletionReq struct {
+	Code string `json:"code" validate:"required,len=6"`
+}
+
+type AccountDeletionResp struct {
+	DeletionScheduledAt string `json:"deletion_scheduled_at"`
+}
+
+type SessionExport struct {
+	ID         int    `json:"session_id"`
+	DeviceName string `json:"device_name"`
+	Platform   string `json:"platform"`
+	IP         string `json:"ip"`
+	UserAgent  string `json:"user_agent"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+}
+
+type MembershipExport struct {
+	ChatID   int    `json:"chat_id"`
+	ChatName string `json:"chat_name"`
+	ChatType string `json:"chat_type"`
+	Admin    bool   `json:"admin"`
+	JoinedAt string `json:"joined_at"`
+}
+
+type ChangePhoneReq struct {
+	Phone     string `json:"phone" validate:"required,e164"`
+	VerifyOld bool   `json:"verify_old"`
+}
+
+type ConfirmPhoneChangeReq struct {
+	NewCode string `json:"new_code" validate:"required,len=6"`
+	OldCode string `json:"old_code" validate:"omitempty,len=6"`
+}
+
+// PhoneChange is a phone change waiting for its codes, OldCode is empty
+// when the old number is not verified.
+type PhoneChange struct {
+	Phone   string `json:"phone"`
+	NewCode string `json:"new_code"`
+	OldCode string `json:"old_code"`
+}
+
+type PhoneChangedResp struct {
+	UserID int    `json:"user_id"`
+	Phone  string `json:"phone"`
+}
@@ -1,10 +1,63 @@
 package core
 
+// Token is a login session of the user on one device, the refresh token is
+// replaced on every refresh while the session keeps its id. Only the SHA-256
+// of the refresh token is stored.
 type Token struct {
-	ID           int `gorm:"primaryKey;autoIncrement"`
-	UserID       int
-	RefreshToken string
-	CreatedAt    string
+	ID               int `gorm:"primaryKey;autoIncrement"`
+	UserID           int
+	RefreshTokenHash string `gorm:"index"`
+	CreatedAt        string
+	LastUsedAt       string
+	DeviceName       string
+	Platform         string
+	IP               string
+	UserAgent        string
+}
+
+// RotatedToken is a refresh token of the session that was already replaced.
+// The session is the family of its refresh tokens, presenting a rotated
+// token again revokes the whole session.
+type RotatedToken struct {
+	ID               int    `gorm:"primaryKey;autoIncrement"`
+	SessionID        int    `gorm:"index"`
+	UserID           int    `gorm:"index"`
+	RefreshTokenHash string `gorm:"uniqueIndex"`
+	RotatedAt        string `gorm:"index"`
+}
+
+const (
+	SecurityEventTokenReuse = "refresh_token_reuse"
+)
+
+// SecurityEvent records a suspicious action on the account of the user.
+type SecurityEvent struct {
+	ID        int `gorm:"primaryKey;autoIncrement"`
+	UserID    int `gorm:"index"`
+	SessionID int
+	Type      string
+	IP        string
+	UserAgent string
+	CreatedAt string
+}
+
+// SessionInfo describes the device a request comes from.
+type SessionInfo struct {
+	DeviceName string
+	Platform   string
+	IP         string
+	UserAgent  string
+}
+
+type SessionResp struct {
+	ID         int    `json:"session_id"`
+	DeviceName string `json:"device_name"`
+	Platform   string `json:"platform"`
+	IP         string `json:"ip"`
+	UserAgent  string `json:"user_agent"`
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+	Current    bool   `json:"current"`
 }
 
 type AuthRegister struct {
@@ -15,3 +68,14 @@ type AuthRegister struct {
 type AuthLogin struct {
 	Phone string `json:"phone" validate:"required"`
 }
+
+type AuthVerify struct {
+	Phone string `json:"phone" validate:"required"`
+	Code  string `json:"code" validate:"required,len=6"`
+}
+
+// VerifyCode is the login code sent to a phone.
+type VerifyCode struct {
+	UserID int    `json:"user_id"`
+	Code   string `json:"code"`
+}
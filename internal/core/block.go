@@ -0,0 +1,21 @@
+package core
+
+// Block is an entry of the block list of UserID. A blocked user cannot
+// start a direct chat with the blocker, message them directly, view
+// their profile or add them to groups.
+type Block struct {
+	UserID        int `gorm:"primaryKey;autoIncrement:false"`
+	BlockedUserID int `gorm:"primaryKey;autoIncrement:false"`
+	CreatedAt     string
+	BlockedUser   User `gorm:"foreignKey:BlockedUserID;constraint:OnDelete:CASCADE;"`
+}
+
+type BlockUserReq struct {
+	UserID int `json:"user_id" validate:"required"`
+}
+
+type BlockedUserResp struct {
+	UserID    int    `json:"user_id"`
+	Username  string `json:"username"`
+	BlockedAt string `json:"blocked_at"`
+}
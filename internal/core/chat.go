@@ -3,16 +3,47 @@ package core
 var (
 	DefaultChatType = "default-chat"
 	GroupChatType   = "group-chat"
+	ChannelChatType = "channel-chat"
+	SavedChatType   = "saved-chat"
+
+	SavedChatName = "Saved messages"
 )
 
 type Chat struct {
+	ID              int `gorm:"primaryKey;autoIncrement"`
+	Name            string
+	Description     string
+	AvatarKey       string
+	Handle          *string `gorm:"unique"`
+	SignedPosts     bool
+	JoinApproval    bool
+	OnlyAdminsPost  bool
+	SlowModeSeconds int
+	TopicsEnabled   bool
+	AdminID         int
+	Type            string
+	CreatedAt       string
+	Users           []ChatUser    `gorm:"constraint:OnDelete:CASCADE;"`
+	Messages        []ChatMessage `gorm:"constraint:OnDelete:CASCADE;"`
+	JoinRequests    []JoinRequest `gorm:"constraint:OnDelete:CASCADE;"`
+	Topics          []ChatTopic   `gorm:"constraint:OnDelete:CASCADE;"`
+	Direct          *DirectChat   `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// DirectChat keeps direct chats unique per unordered pair of users,
+// UserID always holds the lower id. A saved messages chat is stored
+// as a pair of the user with themselves.
+type DirectChat struct {
+	UserID int `gorm:"primaryKey;autoIncrement:false"`
+	PeerID int `gorm:"primaryKey;autoIncrement:false"`
+	ChatID int `gorm:"uniqueIndex"`
+}
+
+type JoinRequest struct {
 	ID        int `gorm:"primaryKey;autoIncrement"`
-	Name      string
-	AdminID   int
-	Type      string
+	ChatID    int `gorm:"uniqueIndex:idx_join_request_chat_user"`
+	UserID    int `gorm:"uniqueIndex:idx_join_request_chat_user"`
 	CreatedAt string
-	Users     []ChatUser    `gorm:"constraint:OnDelete:CASCADE;"`
-	Messages  []ChatMessage `gorm:"constraint:OnDelete:CASCADE;"`
 }
 
 type CreateChatGroupReq struct {
@@ -20,7 +51,18 @@ type CreateChatGroupReq struct {
 }
 
 type CreateDefaultChatReq struct {
-	Phone string `json:"phone" validate:"required"`
+	Phone    string `json:"phone" validate:"required_without=Username"`
+	Username string `json:"username" validate:"required_without=Phone"`
+}
+
+type CreateChannelReq struct {
+	Name        string `json:"chat_name" validate:"required"`
+	Handle      string `json:"handle" validate:"required,handle"`
+	SignedPosts bool   `json:"signed_posts"`
+}
+
+type JoinChannelReq struct {
+	Handle string `json:"handle" validate:"required"`
 }
 
 type UpdateGroupChatAdminReq struct {
@@ -33,16 +75,119 @@ type UpdateGroupChatNameReq struct {
 	ChatID int    `json:"chat_id" validate:"required"`
 }
 
+type UpdateGroupChatDescriptionReq struct {
+	Description string `json:"description" validate:"lte=255"`
+	ChatID      int    `json:"chat_id" validate:"required"`
+}
+
+type UpdateGroupChatSettingsReq struct {
+	ChatID          int   `json:"chat_id" validate:"required"`
+	JoinApproval    *bool `json:"join_approval"`
+	SignedPosts     *bool `json:"signed_posts"`
+	OnlyAdminsPost  *bool `json:"only_admins_post"`
+	SlowModeSeconds *int  `json:"slow_mode_seconds" validate:"omitempty,gte=0,lte=86400"`
+	TopicsEnabled   *bool `json:"topics_enabled"`
+}
+
+type JoinRequestDecisionReq struct {
+	RequestID int `json:"request_id" validate:"required"`
+}
+
+type JoinRequestResp struct {
+	RequestID int    `json:"request_id"`
+	ChatID    int    `json:"chat_id"`
+	ChatName  string `json:"chat_name"`
+	UserID    int    `json:"user_id"`
+	Username  string `json:"username"`
+	CreatedAt string `json:"created_at"`
+}
+
+type LeaveChatGroupAdminReq struct {
+	ChatID      int `json:"chat_id" validate:"required"`
+	SuccessorID int `json:"successor_id"`
+}
+
+type ChatAdminLeftResp struct {
+	ChatID        int  `json:"chat_id"`
+	FormerAdminID int  `json:"former_admin_id"`
+	NewAdminID    int  `json:"new_admin_id,omitempty"`
+	ChatDeleted   bool `json:"chat_deleted"`
+}
+
 type JoinChatGroupReq struct {
 	ChatID int `json:"chat_id" validate:"required"`
 }
 
+type AddChatGroupMemberReq struct {
+	ChatID int `json:"chat_id" validate:"required"`
+	UserID int `json:"user_id" validate:"required"`
+}
+
+type ChatIDResp struct {
+	ChatID int `json:"chat_id"`
+}
+
+type ChannelSubscribersResp struct {
+	ChatID           int   `json:"chat_id"`
+	SubscribersCount int64 `json:"subscribers_count"`
+}
+
+// ChatInfoResp describes a chat. MembersCount of a channel counts its
+// subscribers without the admin, the same as ChannelSubscribersResp.
+type ChatInfoResp struct {
+	ChatID          int    `json:"chat_id"`
+	Type            string `json:"type"`
+	Name            string `json:"chat_name"`
+	Description     string `json:"description"`
+	AvatarUrl       string `json:"avatar_url"`
+	MembersCount    int64  `json:"members_count"`
+	AdminID         int    `json:"admin_id,omitempty"`
+	JoinApproval    bool   `json:"join_approval"`
+	SignedPosts     bool   `json:"signed_posts"`
+	OnlyAdminsPost  bool   `json:"only_admins_post"`
+	SlowModeSeconds int    `json:"slow_mode_seconds"`
+	TopicsEnabled   bool   `json:"topics_enabled"`
+	CreatedAt       string `json:"created_at"`
+}
+
+// ForViewer hides the admin of a channel with unsigned posts from everyone
+// but the admin, the posts do not tell who wrote them either.
+func (c *ChatInfoResp) ForViewer(viewerId int) *ChatInfoResp {
+	if c.Type != ChannelChatType || c.SignedPosts || c.AdminID == viewerId {
+		return c
+	}
+
+	hidden := *c
+	hidden.AdminID = 0
+
+	return &hidden
+}
+
 type WallChatsResp struct {
 	Data []WallChatResp `json:"data"`
 }
 
+type UpdateChatPrefsReq struct {
+	ChatID     int     `json:"chat_id" validate:"required"`
+	Archived   *bool   `json:"archived"`
+	Pinned     *bool   `json:"pinned"`
+	MutedUntil *string `json:"muted_until" validate:"omitempty,datetime=2006-01-02 15:04:05"`
+}
+
+type ReorderPinnedChatsReq struct {
+	ChatIDs []int `json:"chat_ids" validate:"required,min=1"`
+}
+
 type WallChatResp struct {
 	ChatID      int    `json:"chat_id"`
 	Name        string `json:"chat_name"`
 	LastMessage string `json:"chat_last_message"`
+	Archived    bool   `json:"archived"`
+	Pinned      bool   `json:"pinned"`
+	Muted       bool   `json:"muted"`
+	MutedUntil  string `json:"muted_until,omitempty"`
+	UnreadCount int64  `json:"unread_count"`
+
+	// Topics is set for groups in topics mode.
+	Topics []*ChatTopicResp `json:"topics,omitempty"`
 }
@@ -0,0 +1,35 @@
+package core
+
+// Contact is an entry of the address book of UserID. Name is the custom
+// name the owner gave to the contact, it overrides the username of the
+// contact in the views of the owner.
+type Contact struct {
+	UserID        int `gorm:"primaryKey;autoIncrement:false"`
+	ContactUserID int `gorm:"primaryKey;autoIncrement:false"`
+	Name          string
+	ContactUser   User `gorm:"foreignKey:ContactUserID;constraint:OnDelete:CASCADE;"`
+}
+
+// SyncContactsReq holds the address book of the client. Hashes are hex
+// encoded sha256 of the salt followed by the phone number in E.164 form.
+type SyncContactsReq struct {
+	Phones []string `json:"phones" validate:"max=1000"`
+	Hashes []string `json:"hashes" validate:"max=1000,dive,len=64,hexadecimal"`
+}
+
+type UpdateContactReq struct {
+	ContactUserID int    `json:"contact_user_id" validate:"required"`
+	Name          string `json:"name" validate:"lte=64"`
+}
+
+type ContactResp struct {
+	UserID    int    `json:"user_id"`
+	Username  string `json:"username"`
+	Name      string `json:"name,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+	PhoneHash string `json:"phone_hash,omitempty"`
+}
+
+type ContactsSaltResp struct {
+	Salt string `json:"salt"`
+}
@@ -2,6 +2,9 @@ package core
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -13,10 +16,15 @@ var (
 	ErrEmptyAccessToken   = errors.New("access token is empty")
 	ErrInvalidAccessToken = errors.New("invalid access token")
 	ErrExpiredAccessToken = errors.New("expired access token")
+	ErrRevokedAccessToken = errors.New("revoked access token")
 
 	ErrEmptyRefreshToken     = errors.New("refresh token is empty")
 	ErrRefreshTokenNotFound  = errors.New("refresh token not found")
 	ErrRefreshTokenIsExpired = errors.New("refresh token not found")
+	ErrRefreshTokenReused    = errors.New("refresh token was already used, the session is revoked")
+
+	ErrSessionNotFound = errors.New("session not found")
+	ErrEmptySessionID  = errors.New("session id is empty")
 
 	ErrUserNotFound      = errors.New("user not found")
 	ErrEmptyUserID       = errors.New("user id is empty")
@@ -37,6 +45,10 @@ var (
 
 	ErrEmptyAvatarID  = errors.New("avatar id is empty")
 	ErrAvatarNotFound = errors.New("avatar not found")
+	ErrInvalidImage   = errors.New("file is not a supported image")
+	ErrImageTooLarge  = errors.New("image is too large")
+
+	ErrInvalidAvatarOrder = errors.New("list must contain every avatar exactly once")
 
 	ErrStreamNotAvailable = errors.New("stream not available")
 	ErrStreamIsClosed     = errors.New("stream is closed")
@@ -48,4 +60,69 @@ var (
 	ErrConnotJoinChat = errors.New("cannot join chat")
 	ErrJoinIsAlready  = errors.New("join is already")
 	ErrChatGroupFull  = errors.New("chat group is full")
+
+	ErrHandleIsTaken    = errors.New("handle is already taken")
+	ErrChannelNotFound  = errors.New("channel not found")
+	ErrOnlyAdminCanPost = errors.New("only admin can post in this chat")
+
+	ErrNotChatMember = errors.New("you are not a member of this chat")
+
+	ErrJoinApprovalRequired = errors.New("join requires admin approval")
+	ErrJoinRequestNotFound  = errors.New("join request not found")
+
+	ErrCannotChatWithSelf = errors.New("use saved messages to chat with yourself")
+	ErrDirectChatExists   = errors.New("direct chat already exists")
+	ErrMessageNotFound    = errors.New("message not found")
+
+	ErrSuccessorNotMember = errors.New("successor is not a member of this chat")
+
+	ErrInvalidPinnedChats = errors.New("list must contain every pinned chat exactly once")
+
+	ErrEmptyFolderID      = errors.New("folder id is empty")
+	ErrFolderNotFound     = errors.New("folder not found")
+	ErrTooManyFolders     = errors.New("too many folders")
+	ErrInvalidFolderOrder = errors.New("list must contain every folder exactly once")
+
+	ErrTopicNotFound   = errors.New("topic not found")
+	ErrTopicRequired   = errors.New("topic is required in topics mode")
+	ErrTopicsDisabled  = errors.New("topics are disabled in this chat")
+	ErrTopicsGroupOnly = errors.New("topics are available only in groups")
+	ErrEmptySearchText = errors.New("search text is empty")
+
+	ErrEmptyContacts   = errors.New("contacts are empty")
+	ErrContactNotFound = errors.New("contact not found")
+
+	ErrCannotBlockSelf = errors.New("you cannot block yourself")
+	ErrBlockedByUser   = errors.New("you are blocked by this user")
+
+	ErrPrivacyRestricted  = errors.New("the privacy settings of this user do not allow it")
+	ErrConflictingPrivacy = errors.New("user cannot be both always and never allowed")
+
+	ErrTooManyRequests  = errors.New("too many requests, try again later")
+	ErrTooManyAttempts  = errors.New("too many failed attempts, try again later")
+	ErrCodeSentTooOften = errors.New("code was sent too often, try again later")
+
+	ErrStatusExpired      = errors.New("status expiry must be in the future")
+	ErrStatusExpiryNoText = errors.New("status expiry requires a status text")
+
+	ErrDeletionAlreadyScheduled = errors.New("account deletion is already scheduled")
+	ErrDeletionNotScheduled     = errors.New("account deletion is not scheduled")
+
+	ErrPhoneChangeNotFound = errors.New("no pending phone change")
+	ErrSamePhone           = errors.New("new phone is the same as the current one")
+	ErrInvalidPhoneCode    = errors.New("invalid phone change code")
 )
+
+// SlowModeError is returned when a member posts to a slow mode chat
+// before their cooldown has passed.
+type SlowModeError struct {
+	Remaining time.Duration
+}
+
+func (e *SlowModeError) Seconds() int {
+	return int(math.Ceil(e.Remaining.Seconds()))
+}
+
+func (e *SlowModeError) Error() string {
+	return fmt.Sprintf("slow mode is enabled, wait %d seconds", e.Seconds())
+}
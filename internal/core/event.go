@@ -6,15 +6,30 @@ var (
 	LeaveChatGroupEventHeader = "LeaveChatGroup"
 	UpdateChatGroupAdmin      = "UpdateChatGroupAdmin"
 	UpdateChatGroupName       = "UpdateChatGroupName"
+	ChatMetadataUpdatedHeader = "ChatMetadataUpdated"
+	JoinRequestedHeader       = "JoinRequested"
+	JoinRequestApprovedHeader = "JoinRequestApproved"
+	JoinRequestDeclinedHeader = "JoinRequestDeclined"
+	AdminLeftChatGroupHeader  = "AdminLeftChatGroup"
+	TopicCreatedHeader        = "TopicCreated"
+	PresenceChangedHeader     = "PresenceChanged"
+	ProfileUpdatedHeader      = "ProfileUpdated"
+	PhoneChangedHeader        = "PhoneChanged"
 )
 
+// Event is delivered in realtime even when the receiver muted the chat,
+// Silent tells the client not to raise a notification for it.
 type Event struct {
 	Header        string
 	Message       *ChatMessage
+	Payload       any
+	Silent        bool
 	ReceiveUserID int
 }
 
 type EventResponse struct {
 	Header  string
-	Message *ChatMessage
+	Message *ChatMessage `json:",omitempty"`
+	Payload any          `json:",omitempty"`
+	Silent  bool         `json:",omitempty"`
 }
@@ -0,0 +1,95 @@
+package core
+
+// ChatFolder groups chats on the wall of its owner. A chat belongs to the
+// folder when it is listed explicitly or matches one of the include rules,
+// a folder without any of them includes every chat. UnreadOnly and
+// ExcludeMuted narrow the result down afterwards.
+type ChatFolder struct {
+	ID              int `gorm:"primaryKey;autoIncrement"`
+	UserID          int `gorm:"index"`
+	Name            string
+	Position        int
+	IncludeDirect   bool
+	IncludeGroups   bool
+	IncludeChannels bool
+	UnreadOnly      bool
+	ExcludeMuted    bool
+	Chats           []ChatFolderChat `gorm:"foreignKey:FolderID;constraint:OnDelete:CASCADE;"`
+}
+
+type ChatFolderChat struct {
+	FolderID int `gorm:"primaryKey;autoIncrement:false"`
+	ChatID   int `gorm:"primaryKey;autoIncrement:false"`
+}
+
+type ChatFolderReq struct {
+	Name            string `json:"name" validate:"required,lte=32"`
+	ChatIDs         []int  `json:"chat_ids"`
+	IncludeDirect   bool   `json:"include_direct"`
+	IncludeGroups   bool   `json:"include_groups"`
+	IncludeChannels bool   `json:"include_channels"`
+	UnreadOnly      bool   `json:"unread_only"`
+	ExcludeMuted    bool   `json:"exclude_muted"`
+}
+
+type UpdateChatFolderReq struct {
+	FolderID int `json:"folder_id" validate:"required"`
+	ChatFolderReq
+}
+
+type ReorderChatFoldersReq struct {
+	FolderIDs []int `json:"folder_ids" validate:"required,min=1"`
+}
+
+type ChatFolderResp struct {
+	FolderID        int    `json:"folder_id"`
+	Name            string `json:"name"`
+	Position        int    `json:"position"`
+	ChatIDs         []int  `json:"chat_ids"`
+	IncludeDirect   bool   `json:"include_direct"`
+	IncludeGroups   bool   `json:"include_groups"`
+	IncludeChannels bool   `json:"include_channels"`
+	UnreadOnly      bool   `json:"unread_only"`
+	ExcludeMuted    bool   `json:"exclude_muted"`
+}
+
+func (f *ChatFolder) ChatIDs() []int {
+	chatIds := make([]int, 0, len(f.Chats))
+	for _, chat := range f.Chats {
+		chatIds = append(chatIds, chat.ChatID)
+	}
+
+	return chatIds
+}
+
+// Matches reports whether the wall chat of the given type belongs to the folder.
+func (f *ChatFolder) Matches(chatType string, wallChat *WallChatResp) bool {
+	if f.UnreadOnly && wallChat.UnreadCount == 0 {
+		return false
+	}
+
+	if f.ExcludeMuted && wallChat.Muted {
+		return false
+	}
+
+	if len(f.Chats) == 0 && !f.IncludeDirect && !f.IncludeGroups && !f.IncludeChannels {
+		return true
+	}
+
+	for _, chat := range f.Chats {
+		if chat.ChatID == wallChat.ChatID {
+			return true
+		}
+	}
+
+	switch chatType {
+	case DefaultChatType, SavedChatType:
+		return f.IncludeDirect
+	case GroupChatType:
+		return f.IncludeGroups
+	case ChannelChatType:
+		return f.IncludeChannels
+	}
+
+	return false
+}
@@ -1,26 +1,44 @@
 package core
 
 type ChatMessage struct {
-	ID        int    `gorm:"primaryKey;autoIncrement" json:"chat_message_id"`
-	Username  string `json:"username"`
-	UserID    int    `json:"user_id"`
-	ChatID    int    `json:"chat_id"`
-	Text      string `json:"text"`
-	CreatedAt string `json:"created_at"`
+	ID            int    `gorm:"primaryKey;autoIncrement" json:"chat_message_id"`
+	Username      string `json:"username"`
+	UserID        int    `json:"user_id"`
+	ChatID        int    `json:"chat_id"`
+	TopicID       int    `gorm:"index;not null;default:0" json:"topic_id,omitempty"`
+	Text          string `json:"text"`
+	Signature     string `json:"signature,omitempty"`
+	ForwardedFrom string `json:"forwarded_from,omitempty"`
+	CreatedAt     string `json:"created_at"`
 }
 
 type SendMessageReq struct {
-	ChatID int    `json:"chat_id" validate:"required"`
-	Text   string `json:"text" validate:"required"`
+	ChatID  int    `json:"chat_id" validate:"required"`
+	TopicID int    `json:"topic_id"`
+	Text    string `json:"text" validate:"required"`
+}
+
+type ReadMessagesReq struct {
+	ChatID    int `json:"chat_id" validate:"required"`
+	MessageID int `json:"message_id" validate:"required"`
+}
+
+type ForwardMessageReq struct {
+	MessageID int `json:"message_id" validate:"required"`
+	ChatID    int `json:"chat_id" validate:"required"`
+	TopicID   int `json:"topic_id"`
 }
 
 func PtrMsgToNonePtrMsg(event *ChatMessage) ChatMessage {
 	return ChatMessage{
-		ID:        event.ID,
-		Username:  event.Username,
-		UserID:    event.UserID,
-		ChatID:    event.ChatID,
-		Text:      event.Text,
-		CreatedAt: event.CreatedAt,
+		ID:            event.ID,
+		Username:      event.Username,
+		UserID:        event.UserID,
+		ChatID:        event.ChatID,
+		TopicID:       event.TopicID,
+		Text:          event.Text,
+		Signature:     event.Signature,
+		ForwardedFrom: event.ForwardedFrom,
+		CreatedAt:     event.CreatedAt,
 	}
 }
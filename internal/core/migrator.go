@@ -3,5 +3,88 @@ package core
 import "gorm.io/gorm"
 
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(&User{}, &Token{}, &Chat{}, &ChatUser{}, &ChatMessage{}, &UserAvatar{})
+	// read positions are backfilled only once, when the column is added
+	readTracked := db.Migrator().HasColumn(&ChatUser{}, "last_read_message_id")
+
+	if err := db.AutoMigrate(&User{}, &Token{}, &Chat{}, &ChatUser{}, &ChatMessage{}, &UserAvatar{}, &JoinRequest{}, &DirectChat{},
+		&ChatFolder{}, &ChatFolderChat{}, &ChatTopic{}, &ChatTopicRead{},
+		&Contact{}, &Block{}, &PrivacySetting{}, &PrivacyException{},
+		&RotatedToken{}, &SecurityEvent{}); err != nil {
+		return err
+	}
+
+	if err := backfillDirectChats(db); err != nil {
+		return err
+	}
+
+	if !readTracked {
+		if err := backfillLastRead(db); err != nil {
+			return err
+		}
+	}
+
+	if err := backfillSessionLastUsed(db); err != nil {
+		return err
+	}
+
+	if err := hashRefreshTokens(db); err != nil {
+		return err
+	}
+
+	return createUsernamePrefixIndex(db)
+}
+
+// backfillDirectChats registers direct chats created before pairs were
+// tracked. When a pair already has several chats only the oldest is kept
+// as the direct chat of that pair.
+func backfillDirectChats(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO direct_chats (user_id, peer_id, chat_id)
+		SELECT MIN(chat_users.user_id), MAX(chat_users.user_id), chats.id
+		FROM chats
+		JOIN chat_users ON chat_users.chat_id = chats.id
+		WHERE chats.type = ?
+		GROUP BY chats.id
+		HAVING COUNT(*) = 2 AND COUNT(DISTINCT chat_users.user_id) = 2
+		ORDER BY chats.id
+		ON CONFLICT DO NOTHING`, DefaultChatType).Error
+}
+
+// backfillLastRead marks the history of memberships created before reads
+// were tracked as read, so it does not all show up as unread.
+func backfillLastRead(db *gorm.DB) error {
+	return db.Exec(`
+		UPDATE chat_users SET last_read_message_id = latest.id
+		FROM (SELECT chat_id, MAX(id) AS id FROM chat_messages GROUP BY chat_id) AS latest
+		WHERE latest.chat_id = chat_users.chat_id AND COALESCE(chat_users.last_read_message_id, 0) = 0`).Error
+}
+
+// backfillSessionLastUsed fills the last used time of sessions created
+// before it was tracked, session expiry is counted from it.
+func backfillSessionLastUsed(db *gorm.DB) error {
+	return db.Exec(`UPDATE tokens SET last_used_at = created_at WHERE last_used_at IS NULL OR last_used_at = ''`).Error
+}
+
+// hashRefreshTokens replaces the plain refresh tokens stored before only
+// their hashes were kept, existing sessions stay logged in. The hash matches
+// the one of token.HashRefreshToken.
+func hashRefreshTokens(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&Token{}, "refresh_token") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			UPDATE tokens SET refresh_token_hash = encode(sha256(convert_to(refresh_token, 'UTF8')), 'hex')
+			WHERE refresh_token IS NOT NULL AND refresh_token <> ''`).Error; err != nil {
+			return err
+		}
+
+		return tx.Migrator().DropColumn(&Token{}, "refresh_token")
+	})
+}
+
+// createUsernamePrefixIndex backs the case insensitive prefix search over usernames.
+func createUsernamePrefixIndex(db *gorm.DB) error {
+	return db.Exec(`CREATE INDEX IF NOT EXISTS idx_users_username_prefix ON users (lower(username) text_pattern_ops)`).Error
 }
@@ -0,0 +1,13 @@
+package core
+
+type PresenceReq struct {
+	UserIDs []int `json:"user_ids" validate:"required,min=1,max=100"`
+}
+
+// PresenceResp tells whether the user has an open stream on any instance,
+// LastSeen is set for offline users that have been online at least once.
+type PresenceResp struct {
+	UserID   int    `json:"user_id"`
+	Online   bool   `json:"online"`
+	LastSeen string `json:"last_seen,omitempty"`
+}
@@ -0,0 +1,52 @@
+package core
+
+const (
+	PrivacyPhone    = "phone"
+	PrivacyLastSeen = "last_seen"
+	PrivacyAvatar   = "avatar"
+	PrivacyGroupAdd = "group_add"
+
+	PrivacyEverybody = "everybody"
+	PrivacyContacts  = "contacts"
+	PrivacyNobody    = "nobody"
+)
+
+// PrivacySettings lists every setting with the level used until
+// the user changes it.
+var PrivacySettings = map[string]string{
+	PrivacyPhone:    PrivacyContacts,
+	PrivacyLastSeen: PrivacyEverybody,
+	PrivacyAvatar:   PrivacyEverybody,
+	PrivacyGroupAdd: PrivacyEverybody,
+}
+
+// PrivacySetting decides who besides the user can see the data covered by
+// Setting. Contacts are the users in the address book of the user.
+type PrivacySetting struct {
+	UserID  int    `gorm:"primaryKey;autoIncrement:false"`
+	Setting string `gorm:"primaryKey"`
+	Level   string
+}
+
+// PrivacyException overrides the level of the setting for one user,
+// Allow tells whether the user is always or never allowed.
+type PrivacyException struct {
+	UserID       int    `gorm:"primaryKey;autoIncrement:false"`
+	Setting      string `gorm:"primaryKey"`
+	TargetUserID int    `gorm:"primaryKey;autoIncrement:false"`
+	Allow        bool
+}
+
+type UpdatePrivacyReq struct {
+	Setting     string `json:"setting" validate:"required,oneof=phone last_seen avatar group_add"`
+	Level       string `json:"level" validate:"required,oneof=everybody contacts nobody"`
+	AlwaysAllow []int  `json:"always_allow" validate:"max=100"`
+	NeverAllow  []int  `json:"never_allow" validate:"max=100"`
+}
+
+type PrivacySettingResp struct {
+	Setting     string `json:"setting"`
+	Level       string `json:"level"`
+	AlwaysAllow []int  `json:"always_allow"`
+	NeverAllow  []int  `json:"never_allow"`
+}
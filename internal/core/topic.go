@@ -0,0 +1,34 @@
+package core
+
+const GeneralTopicName = "General"
+
+// ChatTopic is a named thread of a group in topics mode, every message
+// of such a group belongs to one of its topics.
+type ChatTopic struct {
+	ID        int `gorm:"primaryKey;autoIncrement"`
+	ChatID    int `gorm:"index"`
+	Name      string
+	CreatedAt string
+	Reads     []ChatTopicRead `gorm:"foreignKey:TopicID;constraint:OnDelete:CASCADE;"`
+}
+
+// ChatTopicRead keeps the read marker of a member per topic.
+type ChatTopicRead struct {
+	UserID            int `gorm:"primaryKey;autoIncrement:false"`
+	TopicID           int `gorm:"primaryKey;autoIncrement:false"`
+	LastReadMessageID int
+}
+
+type CreateChatTopicReq struct {
+	ChatID int    `json:"chat_id" validate:"required"`
+	Name   string `json:"name" validate:"required,lte=64"`
+}
+
+type ChatTopicResp struct {
+	TopicID     int    `json:"topic_id"`
+	ChatID      int    `json:"chat_id"`
+	Name        string `json:"name"`
+	LastMessage string `json:"last_message,omitempty"`
+	UnreadCount int64  `json:"unread_count"`
+	CreatedAt   string `json:"created_at"`
+}
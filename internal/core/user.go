@@ -1,19 +1,76 @@
 package core
 
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
 type User struct {
 	ID          int          `gorm:"primaryKey;autoIncrement" json:"user_id"`
 	Phone       string       `gorm:"unique" json:"phone"`
+	PhoneHash   string       `gorm:"index" json:"-"`
 	Username    string       `gorm:"unique" json:"username"`
 	UserAvatars []UserAvatar `json:"image"`
+
+	DisplayName     string `json:"display_name"`
+	Bio             string `json:"bio"`
+	StatusText      string `json:"status_text"`
+	StatusExpiresAt string `json:"status_expires_at"`
+
+	DeletionScheduledAt string `gorm:"index;not null;default:''" json:"-"`
+
+	// CurrentAvatarID is zero until the user picks an avatar, the last one
+	// in the gallery is shown then.
+	CurrentAvatarID int `gorm:"not null;default:0" json:"current_avatar_id"`
+}
+
+// ActiveStatus returns the status text and its expiry, both are empty once
+// the status has expired.
+func (u *User) ActiveStatus(now time.Time) (string, string) {
+	if u.StatusExpiresAt == "" {
+		return u.StatusText, ""
+	}
+
+	expiresAt, err := time.ParseInLocation(time.DateTime, u.StatusExpiresAt, time.Local)
+	if err != nil || !now.Before(expiresAt) {
+		return "", ""
+	}
+
+	return u.StatusText, u.StatusExpiresAt
 }
+
 type ChatUser struct {
-	UserID int `gorm:"primaryKey"`
-	ChatID int `gorm:"primaryKey"`
+	UserID            int `gorm:"primaryKey"`
+	ChatID            int `gorm:"primaryKey"`
+	JoinedAt          string
+	Archived          bool
+	PinOrder          int
+	MutedUntil        string
+	LastReadMessageID int
+}
+
+// IsMuted reports whether the user has muted the chat at the given moment.
+func (c *ChatUser) IsMuted(now time.Time) bool {
+	if c.MutedUntil == "" {
+		return false
+	}
+
+	mutedUntil, err := time.ParseInLocation(time.DateTime, c.MutedUntil, time.Local)
+	if err != nil {
+		return false
+	}
+
+	return now.Before(mutedUntil)
 }
 
+// UserAvatar lists the square sizes stored for the avatar, avatars uploaded
+// before the images were processed have no sizes and keep the original.
 type UserAvatar struct {
-	ID     int `gorm:"primaryKey;autoIncrement"`
-	UserID int
+	ID       int `gorm:"primaryKey;autoIncrement"`
+	UserID   int
+	Sizes    pq.Int64Array `gorm:"type:integer[]"`
+	Position int           `gorm:"not null;default:0"`
 }
 
 type GetProfileResp struct {
@@ -21,14 +78,70 @@ type GetProfileResp struct {
 	Phone     string `json:"phone"`
 	Username  string `json:"name"`
 	AvatarUrl string `json:"avatar_url"`
+
+	AvatarUrls map[int64]string `json:"avatar_urls,omitempty"`
+
+	DisplayName     string `json:"display_name,omitempty"`
+	Bio             string `json:"bio,omitempty"`
+	StatusText      string `json:"status_text,omitempty"`
+	StatusExpiresAt string `json:"status_expires_at,omitempty"`
+
+	DeletionScheduledAt string `json:"deletion_scheduled_at,omitempty"`
+}
+
+// UserProfileResp is the profile of a user as seen by other users.
+type UserProfileResp struct {
+	ID        int    `json:"user_id"`
+	Username  string `json:"name"`
+	Phone     string `json:"phone,omitempty"`
+	AvatarUrl string `json:"avatar_url,omitempty"`
+
+	AvatarUrls map[int64]string `json:"avatar_urls,omitempty"`
+
+	DisplayName     string `json:"display_name,omitempty"`
+	Bio             string `json:"bio,omitempty"`
+	StatusText      string `json:"status_text,omitempty"`
+	StatusExpiresAt string `json:"status_expires_at,omitempty"`
+}
+
+type SearchUsersReq struct {
+	Query string `validate:"required,lte=32"`
+	Limit int    `validate:"gte=0,lte=50"`
+}
+
+type UserSearchResp struct {
+	ID          int    `json:"user_id"`
+	Username    string `json:"name"`
+	DisplayName string `json:"display_name,omitempty"`
 }
 
 type GetAllUserAvatarsResp struct {
-	ID        int    `json:"avatar_id"`
-	AvatarUrl string `json:"avatar_url"`
+	ID         int              `json:"avatar_id"`
+	AvatarUrl  string           `json:"avatar_url"`
+	AvatarUrls map[int64]string `json:"avatar_urls,omitempty"`
+	Position   int              `json:"position"`
+	Current    bool             `json:"current"`
+}
+
+type SetCurrentAvatarReq struct {
+	AvatarID int `json:"avatar_id" validate:"required"`
 }
 
+type ReorderAvatarsReq struct {
+	AvatarIDs []int `json:"avatar_ids" validate:"required,min=1"`
+}
+
+// UpdateUserReq no longer changes the phone, see ChangePhoneReq.
 type UpdateUserReq struct {
-	Phone    string `json:"phone" validate:"required"`
 	Username string `json:"name" validate:"required,gte=2"`
 }
+
+// UpdateProfileDetailsReq changes only the fields that are set, an empty
+// string clears the field. Setting a new status text without an expiry
+// makes it permanent.
+type UpdateProfileDetailsReq struct {
+	DisplayName     *string `json:"display_name" validate:"omitempty,lte=64"`
+	Bio             *string `json:"bio" validate:"omitempty,lte=140"`
+	StatusText      *string `json:"status_text" validate:"omitempty,lte=70"`
+	StatusExpiresAt *string `json:"status_expires_at" validate:"omitempty,datetime=2006-01-02 15:04:05"`
+}
@@ -1,17 +1,33 @@
 package core
 
-import "github.com/go-playground/validator/v10"
+import (
+	"regexp"
 
-var validate *validator.Validate
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	validate *validator.Validate
+
+	handleRegexp = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{4,31}$`)
+)
 
 func init() {
 	validate = validator.New()
+
+	validate.RegisterValidation("handle", func(fl validator.FieldLevel) bool {
+		return handleRegexp.MatchString(fl.Field().String())
+	})
 }
 
 func (a *AuthRegister) Validate() error {
 	return validate.Struct(a)
 }
 
+func (a *AuthVerify) Validate() error {
+	return validate.Struct(a)
+}
+
 func (a *AuthLogin) Validate() error {
 	return validate.Struct(a)
 }
@@ -20,6 +36,30 @@ func (u *UpdateUserReq) Validate() error {
 	return validate.Struct(u)
 }
 
+func (u *UpdateProfileDetailsReq) Validate() error {
+	return validate.Struct(u)
+}
+
+func (c *ConfirmAccountDeletionReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (c *ChangePhoneReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (c *ConfirmPhoneChangeReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (s *SetCurrentAvatarReq) Validate() error {
+	return validate.Struct(s)
+}
+
+func (r *ReorderAvatarsReq) Validate() error {
+	return validate.Struct(r)
+}
+
 func (s *SendMessageReq) Validate() error {
 	return validate.Struct(s)
 }
@@ -43,3 +83,106 @@ func (c *UpdateGroupChatNameReq) Validate() error {
 func (c *JoinChatGroupReq) Validate() error {
 	return validate.Struct(c)
 }
+
+func (c *CreateChannelReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (c *JoinChannelReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (c *UpdateGroupChatDescriptionReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (c *UpdateGroupChatSettingsReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (c *JoinRequestDecisionReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (f *ForwardMessageReq) Validate() error {
+	return validate.Struct(f)
+}
+
+func (c *LeaveChatGroupAdminReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (c *UpdateChatPrefsReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (c *ReorderPinnedChatsReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (r *ReadMessagesReq) Validate() error {
+	return validate.Struct(r)
+}
+
+func (c *ChatFolderReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (c *UpdateChatFolderReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (c *ReorderChatFoldersReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (c *CreateChatTopicReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (c *SyncContactsReq) Validate() error {
+	if len(c.Phones) == 0 && len(c.Hashes) == 0 {
+		return ErrEmptyContacts
+	}
+
+	return validate.Struct(c)
+}
+
+func (c *UpdateContactReq) Validate() error {
+	return validate.Struct(c)
+}
+
+func (b *BlockUserReq) Validate() error {
+	return validate.Struct(b)
+}
+
+func (a *AddChatGroupMemberReq) Validate() error {
+	return validate.Struct(a)
+}
+
+func (p *PresenceReq) Validate() error {
+	return validate.Struct(p)
+}
+
+func (u *UpdatePrivacyReq) Validate() error {
+	if err := validate.Struct(u); err != nil {
+		return err
+	}
+
+	always := make(map[int]bool, len(u.AlwaysAllow))
+	for _, userId := range u.AlwaysAllow {
+		always[userId] = true
+	}
+
+	for _, userId := range u.NeverAllow {
+		if always[userId] {
+			return ErrConflictingPrivacy
+		}
+	}
+
+	return nil
+}
+
+func (s *SearchUsersReq) Validate() error {
+	return validate.Struct(s)
+}
@@ -0,0 +1,296 @@
+package psql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+	"github.com/sirupsen/logrus"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Account struct {
+	db *gorm.DB
+
+	log *logrus.Logger
+}
+
+func NewAccount(db *gorm.DB, log *logrus.Logger) *Account {
+	return &Account{
+		db: db,
+
+		log: log,
+	}
+}
+
+func (a *Account) GetUserById(ctx context.Context, userId int) (*core.User, error) {
+	var user *core.User
+	result := a.db.Where("id = ?", userId).Limit(1).Find(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 0 {
+		return nil, core.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+func (a *Account) ScheduleDeletion(ctx context.Context, userId int, scheduledAt string) error {
+	result := a.db.Model(core.User{}).
+		Where("id = ? AND deletion_scheduled_at = ''", userId).
+		Update("deletion_scheduled_at", scheduledAt)
+	if result.Error != nil {
+		return result.Error
+	} else if result.RowsAffected == 0 {
+		return core.ErrDeletionAlreadyScheduled
+	}
+
+	return nil
+}
+
+func (a *Account) CancelDeletion(ctx context.Context, userId int) error {
+	result := a.db.Model(core.User{}).
+		Where("id = ? AND deletion_scheduled_at <> ''", userId).
+		Update("deletion_scheduled_at", "")
+	if result.Error != nil {
+		return result.Error
+	} else if result.RowsAffected == 0 {
+		return core.ErrDeletionNotScheduled
+	}
+
+	return nil
+}
+
+// GetUsersDueForDeletion returns the users whose grace period ended before
+// the given moment, formatted as time.DateTime.
+func (a *Account) GetUsersDueForDeletion(ctx context.Context, now string) ([]*core.User, error) {
+	var users []*core.User
+	if err := a.db.
+		Where("deletion_scheduled_at <> '' AND deletion_scheduled_at <= ?", now).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (a *Account) GetAvatars(ctx context.Context, userId int) ([]*core.UserAvatar, error) {
+	var avatars []*core.UserAvatar
+	if err := a.db.Where("user_id = ?", userId).Find(&avatars).Error; err != nil {
+		return nil, err
+	}
+
+	return avatars, nil
+}
+
+// PurgeUser removes the user with everything that belongs to them in one
+// transaction. Messages stay in their chats without an author, chats the
+// user administered go to their longest-standing member and chats left
+// without members are deleted. It returns the avatar keys of deleted chats.
+func (a *Account) PurgeUser(ctx context.Context, userId int) ([]string, error) {
+	var avatarKeys []string
+
+	if err := a.db.Transaction(func(tx *gorm.DB) error {
+		var chatIds []int
+		if err := tx.Model(core.ChatUser{}).Where("user_id = ?", userId).Pluck("chat_id", &chatIds).Error; err != nil {
+			return err
+		}
+
+		var adminChats []*core.Chat
+		if err := tx.Where("admin_id = ?", userId).Find(&adminChats).Error; err != nil {
+			return err
+		}
+
+		for _, chat := range adminChats {
+			var successor core.ChatUser
+			if err := tx.Where("chat_id = ? AND user_id <> ?", chat.ID, userId).
+				Order("joined_at, user_id").
+				First(&successor).Error; err != nil {
+				// the chat has no other members and is deleted below
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					continue
+				}
+
+				return err
+			}
+
+			if err := tx.Model(core.Chat{}).Where("id = ?", chat.ID).Update("admin_id", successor.UserID).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("user_id = ?", userId).Delete(&core.ChatUser{}).Error; err != nil {
+			return err
+		}
+
+		if len(chatIds) != 0 {
+			var emptyChats []*core.Chat
+			if err := tx.Where("id IN ?", chatIds).
+				Where("NOT EXISTS (SELECT 1 FROM chat_users WHERE chat_users.chat_id = chats.id)").
+				Find(&emptyChats).Error; err != nil {
+				return err
+			}
+
+			for _, chat := range emptyChats {
+				if chat.AvatarKey != "" {
+					avatarKeys = append(avatarKeys, chat.AvatarKey)
+				}
+
+				if err := tx.Where("chat_id = ?", chat.ID).Delete(&core.ChatMessage{}).Error; err != nil {
+					return err
+				}
+
+				if err := tx.Where("id = ?", chat.ID).Delete(&core.Chat{}).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := tx.Model(core.ChatMessage{}).Where("user_id = ?", userId).Updates(map[string]any{
+			"user_id":   0,
+			"username":  core.DeletedAccountName,
+			"signature": "",
+		}).Error; err != nil {
+			return err
+		}
+
+		for _, query := range []struct {
+			model any
+			where string
+		}{
+			{&core.Token{}, "user_id = @id"},
+			{&core.RotatedToken{}, "user_id = @id"},
+			{&core.SecurityEvent{}, "user_id = @id"},
+			{&core.UserAvatar{}, "user_id = @id"},
+			{&core.JoinRequest{}, "user_id = @id"},
+			{&core.DirectChat{}, "user_id = @id OR peer_id = @id"},
+			{&core.ChatFolder{}, "user_id = @id"},
+			{&core.ChatTopicRead{}, "user_id = @id"},
+			{&core.Contact{}, "user_id = @id OR contact_user_id = @id"},
+			{&core.Block{}, "user_id = @id OR blocked_user_id = @id"},
+			{&core.PrivacySetting{}, "user_id = @id"},
+			{&core.PrivacyException{}, "user_id = @id OR target_user_id = @id"},
+		} {
+			if err := tx.Where(query.where, map[string]any{"id": userId}).Delete(query.model).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("id = ?", userId).Delete(&core.User{}).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return avatarKeys, nil
+}
+
+func (a *Account) GetProfileExport(ctx context.Context, userId int) (*core.User, error) {
+	var user *core.User
+	if err := a.db.Preload("UserAvatars").First(&user, "id = ?", userId).Error; err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (a *Account) GetSessions(ctx context.Context, userId int) ([]*core.SessionExport, error) {
+	var sessions []*core.SessionExport
+	if err := a.db.Model(core.Token{}).
+		Select("id, device_name, platform, ip, user_agent, created_at, last_used_at").
+		Where("user_id = ?", userId).
+		Order("id").
+		Scan(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (a *Account) GetMemberships(ctx context.Context, userId int) ([]*core.MembershipExport, error) {
+	var memberships []*core.MembershipExport
+	if err := a.db.Model(core.ChatUser{}).
+		Select("chats.id AS chat_id, chats.name AS chat_name, chats.type AS chat_type, chats.admin_id = chat_users.user_id AS admin, chat_users.joined_at").
+		Joins("JOIN chats ON chats.id = chat_users.chat_id").
+		Where("chat_users.user_id = ?", userId).
+		Order("chats.id").
+		Scan(&memberships).Error; err != nil {
+		return nil, err
+	}
+
+	return memberships, nil
+}
+
+func (a *Account) GetUserMessages(ctx context.Context, userId int) ([]*core.ChatMessage, error) {
+	var messages []*core.ChatMessage
+	if err := a.db.Where("user_id = ?", userId).Order("id").Find(&messages).Error; err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (a *Account) IsPhoneTaken(ctx context.Context, phone string) (bool, error) {
+	var count int64
+	if err := a.db.Model(core.User{}).Where("phone = ?", phone).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count != 0, nil
+}
+
+// ChangePhone swaps the phone of the user and removes every other session
+// in one transaction, it returns the ids of the removed sessions.
+func (a *Account) ChangePhone(ctx context.Context, userId, sessionId int, phone, phoneHash string) ([]int, error) {
+	var sessions []*core.Token
+
+	if err := a.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(core.User{}).Where("phone = ? AND id <> ?", phone, userId).Count(&count).Error; err != nil {
+			return err
+		} else if count != 0 {
+			return core.ErrThisCredIsAlready
+		}
+
+		if err := tx.Model(core.User{}).Where("id = ?", userId).Updates(map[string]any{
+			"phone":      phone,
+			"phone_hash": phoneHash,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+			Where("user_id = ? AND id <> ?", userId, sessionId).
+			Delete(&sessions).Error
+	}); err != nil {
+		// The check above does not lock, a concurrent change to the same
+		// phone ends on the unique index instead.
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, core.ErrThisCredIsAlready
+		}
+
+		return nil, err
+	}
+
+	sessionIds := make([]int, 0, len(sessions))
+	for _, session := range sessions {
+		sessionIds = append(sessionIds, session.ID)
+	}
+
+	return sessionIds, nil
+}
+
+// GetContactHolders returns the users who keep the user in their contacts.
+func (a *Account) GetContactHolders(ctx context.Context, userId int) ([]int, error) {
+	var holderIds []int
+	if err := a.db.Model(core.Contact{}).Where("contact_user_id = ?", userId).Pluck("user_id", &holderIds).Error; err != nil {
+		return nil, err
+	}
+
+	return holderIds, nil
+}
+
+func (a *Account) CheckPrivacy(ctx context.Context, ownerId, viewerId int, setting string) (bool, error) {
+	return checkPrivacy(a.db, ownerId, viewerId, setting)
+}
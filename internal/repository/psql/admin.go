@@ -0,0 +1,66 @@
+package psql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LeaveChatAsAdmin removes the admin from the chat and hands the chat over in
+// one transaction. The successor is the given member or, when successorId is
+// zero, the longest-standing one. A chat left without members is deleted.
+func (ws *WebSocket) LeaveChatAsAdmin(ctx context.Context, chatId, adminId, successorId int) (*core.ChatAdminLeftResp, error) {
+	resp := &core.ChatAdminLeftResp{
+		ChatID:        chatId,
+		FormerAdminID: adminId,
+	}
+
+	if err := ws.db.Transaction(func(tx *gorm.DB) error {
+		var chat core.Chat
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&chat, "id = ?", chatId).Error; err != nil {
+			return err
+		}
+
+		if chat.AdminID != adminId {
+			return core.ErrNotAdmin
+		}
+
+		if err := tx.Where("user_id = ? AND chat_id = ?", adminId, chatId).Delete(&core.ChatUser{}).Error; err != nil {
+			return err
+		}
+
+		var successor core.ChatUser
+		query := tx.Where("chat_id = ?", chatId)
+		if successorId != 0 {
+			query = query.Where("user_id = ?", successorId)
+		}
+
+		if err := query.Order("joined_at, user_id").First(&successor).Error; err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			} else if successorId != 0 {
+				return core.ErrSuccessorNotMember
+			}
+
+			resp.ChatDeleted = true
+
+			if err := tx.Where("chat_id = ?", chatId).Delete(&core.ChatMessage{}).Error; err != nil {
+				return err
+			}
+
+			return tx.Where("id = ?", chatId).Delete(&core.Chat{}).Error
+		}
+
+		resp.NewAdminID = successor.UserID
+
+		return tx.Model(core.Chat{}).Where("id = ?", chatId).Update("admin_id", successor.UserID).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type Auth struct {
@@ -43,18 +44,129 @@ func (a *Auth) SetTokenSession(ctx context.Context, input *core.Token) error {
 	return a.db.Create(&input).Error
 }
 
-func (a *Auth) GetTokenSession(ctx context.Context, refreshToken string) (*core.Token, error) {
+func (a *Auth) GetTokenSession(ctx context.Context, refreshTokenHash string) (*core.Token, error) {
 	var token *core.Token
-	if err := a.db.Where("refresh_token = ?", refreshToken).Find(&token).Error; err != nil {
-		return nil, err
+	result := a.db.Where("refresh_token_hash = ?", refreshTokenHash).Limit(1).Find(&token)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 0 {
+		return nil, core.ErrRefreshTokenNotFound
 	}
 
 	return token, nil
 }
 
-func (a *Auth) DeleteTokenSession(ctx context.Context, refreshToken string) error {
-	return a.db.Where("refresh_token = ?", refreshToken).Delete(&core.Token{}).Error
+// UpdateTokenSession stores the new refresh token of the session together
+// with the device it was used from and keeps the replaced one as rotated.
+// It fails with ErrRefreshTokenNotFound when the session no longer holds the
+// replaced token, that is when a concurrent refresh rotated it first.
+// Rotated tokens older than expiredBefore are dropped, they would have
+// expired by now anyway.
+func (a *Auth) UpdateTokenSession(ctx context.Context, session *core.Token, rotated *core.RotatedToken, expiredBefore string) error {
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(core.Token{}).
+			Where("id = ? AND refresh_token_hash = ?", session.ID, rotated.RefreshTokenHash).
+			Updates(map[string]any{
+				"refresh_token_hash": session.RefreshTokenHash,
+				"last_used_at":       session.LastUsedAt,
+				"ip":                 session.IP,
+				"user_agent":         session.UserAgent,
+			})
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected == 0 {
+			return core.ErrRefreshTokenNotFound
+		}
+
+		if err := tx.Create(&rotated).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("rotated_at < ?", expiredBefore).Delete(&core.RotatedToken{}).Error
+	})
+}
+
+func (a *Auth) GetRotatedToken(ctx context.Context, refreshTokenHash string) (*core.RotatedToken, error) {
+	var rotated *core.RotatedToken
+	result := a.db.Where("refresh_token_hash = ?", refreshTokenHash).Limit(1).Find(&rotated)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 0 {
+		return nil, core.ErrRefreshTokenNotFound
+	}
+
+	return rotated, nil
+}
+
+func (a *Auth) GetSessionById(ctx context.Context, sessionId int) (*core.Token, error) {
+	var session *core.Token
+	result := a.db.Where("id = ?", sessionId).Limit(1).Find(&session)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 0 {
+		return nil, core.ErrSessionNotFound
+	}
+
+	return session, nil
+}
+
+// RevokeTokenFamily removes the session with all its rotated tokens and
+// records the security event in one transaction.
+func (a *Auth) RevokeTokenFamily(ctx context.Context, sessionId int, event *core.SecurityEvent) error {
+	return a.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("session_id = ?", sessionId).Delete(&core.RotatedToken{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("id = ?", sessionId).Delete(&core.Token{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&event).Error
+	})
+}
+
+func (a *Auth) DeleteTokenSession(ctx context.Context, refreshTokenHash string) error {
+	return a.db.Where("refresh_token_hash = ?", refreshTokenHash).Delete(&core.Token{}).Error
+
+}
+
+func (a *Auth) GetSessions(ctx context.Context, userId int) ([]*core.Token, error) {
+	var sessions []*core.Token
+	if err := a.db.Where("user_id = ?", userId).Order("last_used_at DESC, id DESC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+func (a *Auth) DeleteSession(ctx context.Context, userId, sessionId int) error {
+	result := a.db.Where("id = ? AND user_id = ?", sessionId, userId).Delete(&core.Token{})
+	if result.Error != nil {
+		return result.Error
+	} else if result.RowsAffected == 0 {
+		return core.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// DeleteOtherSessions removes every session of the user but the given one
+// and returns the ids of the removed sessions.
+func (a *Auth) DeleteOtherSessions(ctx context.Context, userId, sessionId int) ([]int, error) {
+	var sessions []*core.Token
+	if err := a.db.Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("user_id = ? AND id <> ?", userId, sessionId).
+		Delete(&sessions).Error; err != nil {
+		return nil, err
+	}
+
+	sessionIds := make([]int, 0, len(sessions))
+	for _, session := range sessions {
+		sessionIds = append(sessionIds, session.ID)
+	}
 
+	return sessionIds, nil
 }
 
 func (a *Auth) CreateAvatar(ctx context.Context, avatar *core.UserAvatar) error {
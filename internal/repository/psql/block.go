@@ -0,0 +1,59 @@
+package psql
+
+import (
+	"context"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (c *Contacts) GetUserById(ctx context.Context, userId int) (*core.User, error) {
+	var user *core.User
+	result := c.db.Where("id = ?", userId).Limit(1).Find(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 0 {
+		return nil, core.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+func (c *Contacts) BlockUser(ctx context.Context, block *core.Block) error {
+	return c.db.Omit("BlockedUser").Clauses(clause.OnConflict{DoNothing: true}).Create(block).Error
+}
+
+func (c *Contacts) UnblockUser(ctx context.Context, userId, blockedUserId int) error {
+	return c.db.Where("user_id = ? AND blocked_user_id = ?", userId, blockedUserId).Delete(&core.Block{}).Error
+}
+
+func (c *Contacts) GetBlockedUsers(ctx context.Context, userId int) ([]*core.Block, error) {
+	var blocks []*core.Block
+	if err := c.db.Preload("BlockedUser").Where("user_id = ?", userId).Order("created_at DESC").Find(&blocks).Error; err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+func (ws *WebSocket) IsBlocked(ctx context.Context, userId, blockedUserId int) (bool, error) {
+	return isBlocked(ws.db, userId, blockedUserId)
+}
+
+func (p *Profile) IsBlocked(ctx context.Context, userId, blockedUserId int) (bool, error) {
+	return isBlocked(p.db, userId, blockedUserId)
+}
+
+// isBlocked reports whether userId has blockedUserId on the block list.
+func isBlocked(db *gorm.DB, userId, blockedUserId int) (bool, error) {
+	var count int64
+	if err := db.Model(core.Block{}).
+		Where("user_id = ? AND blocked_user_id = ?", userId, blockedUserId).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count != 0, nil
+}
@@ -0,0 +1,28 @@
+package psql
+
+import (
+	"context"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+func (ws *WebSocket) GetChatByHandle(ctx context.Context, handle string) (*core.Chat, error) {
+	var chat *core.Chat
+	result := ws.db.Where("handle = ?", handle).Limit(1).Find(&chat)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 0 {
+		return nil, core.ErrChannelNotFound
+	}
+
+	return chat, nil
+}
+
+func (ws *WebSocket) CountChatSubscribers(ctx context.Context, chatId, adminId int) (int64, error) {
+	var count int64
+	if err := ws.db.Model(core.ChatUser{}).Where("chat_id = ? AND user_id <> ?", chatId, adminId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
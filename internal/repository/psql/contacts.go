@@ -0,0 +1,84 @@
+package psql
+
+import (
+	"context"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+	"github.com/sirupsen/logrus"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type Contacts struct {
+	db *gorm.DB
+
+	log *logrus.Logger
+}
+
+func NewContacts(db *gorm.DB, log *logrus.Logger) *Contacts {
+	return &Contacts{
+		db: db,
+
+		log: log,
+	}
+}
+
+func (c *Contacts) GetUsersByPhoneHashes(ctx context.Context, hashes []string) ([]*core.User, error) {
+	var users []*core.User
+	if err := c.db.Where("phone_hash IN ?", hashes).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// SaveContacts adds the contacts to the address book, existing
+// contacts keep their custom names.
+func (c *Contacts) SaveContacts(ctx context.Context, contacts []*core.Contact) error {
+	return c.db.Omit("ContactUser").Clauses(clause.OnConflict{DoNothing: true}).Create(&contacts).Error
+}
+
+func (c *Contacts) GetContacts(ctx context.Context, userId int) ([]*core.Contact, error) {
+	var contacts []*core.Contact
+	if err := c.db.Preload("ContactUser").Where("user_id = ?", userId).Order("contact_user_id").Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+
+	return contacts, nil
+}
+
+func (c *Contacts) GetContact(ctx context.Context, userId, contactUserId int) (*core.Contact, error) {
+	var contact *core.Contact
+	result := c.db.Preload("ContactUser").Where("user_id = ? AND contact_user_id = ?", userId, contactUserId).Limit(1).Find(&contact)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 0 {
+		return nil, core.ErrContactNotFound
+	}
+
+	return contact, nil
+}
+
+func (c *Contacts) UpdateContactName(ctx context.Context, userId, contactUserId int, name string) error {
+	return c.db.Model(core.Contact{}).
+		Where("user_id = ? AND contact_user_id = ?", userId, contactUserId).
+		Update("name", name).Error
+}
+
+func (c *Contacts) DeleteContact(ctx context.Context, userId, contactUserId int) error {
+	return c.db.Where("user_id = ? AND contact_user_id = ?", userId, contactUserId).Delete(&core.Contact{}).Error
+}
+
+func (c *Contacts) GetUsersWithoutPhoneHash(ctx context.Context, limit int) ([]*core.User, error) {
+	var users []*core.User
+	if err := c.db.Where("phone_hash = '' OR phone_hash IS NULL").Order("id").Limit(limit).Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (c *Contacts) SetPhoneHash(ctx context.Context, userId int, hash string) error {
+	return c.db.Model(core.User{}).Where("id = ?", userId).Update("phone_hash", hash).Error
+}
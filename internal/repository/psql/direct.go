@@ -0,0 +1,58 @@
+package psql
+
+import (
+	"context"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (ws *WebSocket) GetDirectChat(ctx context.Context, userId, peerId int) (*core.DirectChat, error) {
+	var direct *core.DirectChat
+	if err := ws.db.First(&direct, "user_id = ? AND peer_id = ?", userId, peerId).Error; err != nil {
+		return nil, err
+	}
+
+	return direct, nil
+}
+
+// CreateDirectChat creates the chat together with its members and pair record.
+// It returns core.ErrDirectChatExists when a concurrent request has already
+// created a chat for the same pair.
+func (ws *WebSocket) CreateDirectChat(ctx context.Context, chat *core.Chat, direct *core.DirectChat) error {
+	return ws.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(chat).Error; err != nil {
+			return err
+		}
+
+		direct.ChatID = chat.ID
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(direct)
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected == 0 {
+			return core.ErrDirectChatExists
+		}
+
+		members := []*core.ChatUser{{UserID: direct.UserID, ChatID: chat.ID, JoinedAt: chat.CreatedAt}}
+		if direct.PeerID != direct.UserID {
+			members = append(members, &core.ChatUser{UserID: direct.PeerID, ChatID: chat.ID, JoinedAt: chat.CreatedAt})
+		}
+
+		return tx.Create(&members).Error
+	})
+}
+
+func (ws *WebSocket) GetMessageById(ctx context.Context, messageId int) (*core.ChatMessage, error) {
+	var message *core.ChatMessage
+	result := ws.db.Where("id = ?", messageId).Limit(1).Find(&message)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 0 {
+		return nil, core.ErrMessageNotFound
+	}
+
+	return message, nil
+}
@@ -0,0 +1,82 @@
+package psql
+
+import (
+	"context"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+
+	"gorm.io/gorm"
+)
+
+func (ws *WebSocket) CreateChatFolder(ctx context.Context, folder *core.ChatFolder) error {
+	return ws.db.Create(folder).Error
+}
+
+func (ws *WebSocket) GetChatFolders(ctx context.Context, userId int) ([]*core.ChatFolder, error) {
+	var folders []*core.ChatFolder
+	if err := ws.db.Preload("Chats").Where("user_id = ?", userId).Order("position, id").Find(&folders).Error; err != nil {
+		return nil, err
+	}
+
+	return folders, nil
+}
+
+func (ws *WebSocket) GetChatFolder(ctx context.Context, folderId, userId int) (*core.ChatFolder, error) {
+	var folder *core.ChatFolder
+	result := ws.db.Preload("Chats").Where("id = ? AND user_id = ?", folderId, userId).Limit(1).Find(&folder)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 0 {
+		return nil, core.ErrFolderNotFound
+	}
+
+	return folder, nil
+}
+
+// UpdateChatFolder saves the folder rules and replaces its explicit chats.
+func (ws *WebSocket) UpdateChatFolder(ctx context.Context, folder *core.ChatFolder) error {
+	return ws.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(core.ChatFolder{}).Where("id = ? AND user_id = ?", folder.ID, folder.UserID).Updates(map[string]any{
+			"name":             folder.Name,
+			"include_direct":   folder.IncludeDirect,
+			"include_groups":   folder.IncludeGroups,
+			"include_channels": folder.IncludeChannels,
+			"unread_only":      folder.UnreadOnly,
+			"exclude_muted":    folder.ExcludeMuted,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("folder_id = ?", folder.ID).Delete(&core.ChatFolderChat{}).Error; err != nil {
+			return err
+		}
+
+		if len(folder.Chats) == 0 {
+			return nil
+		}
+
+		for i := range folder.Chats {
+			folder.Chats[i].FolderID = folder.ID
+		}
+
+		return tx.Create(&folder.Chats).Error
+	})
+}
+
+func (ws *WebSocket) DeleteChatFolder(ctx context.Context, folderId, userId int) error {
+	return ws.db.Where("id = ? AND user_id = ?", folderId, userId).Delete(&core.ChatFolder{}).Error
+}
+
+func (ws *WebSocket) ReorderChatFolders(ctx context.Context, userId int, folderIds []int) error {
+	return ws.db.Transaction(func(tx *gorm.DB) error {
+		for i, folderId := range folderIds {
+			if err := tx.Model(core.ChatFolder{}).
+				Where("id = ? AND user_id = ?", folderId, userId).
+				Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,94 @@
+package psql
+
+import (
+	"context"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (ws *WebSocket) CreateJoinRequest(ctx context.Context, req *core.JoinRequest) error {
+	return ws.db.Where("chat_id = ? AND user_id = ?", req.ChatID, req.UserID).FirstOrCreate(req).Error
+}
+
+func (ws *WebSocket) GetJoinRequest(ctx context.Context, requestId int) (*core.JoinRequest, error) {
+	var request *core.JoinRequest
+	result := ws.db.Where("id = ?", requestId).Limit(1).Find(&request)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 0 {
+		return nil, core.ErrJoinRequestNotFound
+	}
+
+	return request, nil
+}
+
+func (ws *WebSocket) GetJoinRequests(ctx context.Context, chatId int) ([]*core.JoinRequest, error) {
+	var requests []*core.JoinRequest
+	if err := ws.db.Where("chat_id = ?", chatId).Order("id").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+
+	return requests, nil
+}
+
+func (ws *WebSocket) DeleteJoinRequest(ctx context.Context, requestId int) error {
+	return ws.db.Where("id = ?", requestId).Delete(&core.JoinRequest{}).Error
+}
+
+// ApproveJoinRequest adds the requester to the group and saves the join
+// message in one transaction. A requester who became a member in the
+// meantime is left as is and no message is saved, it reports whether the
+// requester was added. The request is removed either way.
+func (ws *WebSocket) ApproveJoinRequest(ctx context.Context, request *core.JoinRequest, maxMembers int, msg *core.ChatMessage) (bool, error) {
+	joined := false
+
+	if err := ws.db.Transaction(func(tx *gorm.DB) error {
+		// parallel approvals of the request wait for each other here
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", request.ID).Limit(1).Find(&core.JoinRequest{})
+		if result.Error != nil {
+			return result.Error
+		} else if result.RowsAffected == 0 {
+			return core.ErrJoinRequestNotFound
+		}
+
+		var isMember int64
+		if err := tx.Model(core.ChatUser{}).Where("user_id = ? AND chat_id = ?", request.UserID, request.ChatID).Count(&isMember).Error; err != nil {
+			return err
+		}
+
+		if isMember == 0 {
+			var members int64
+			if err := tx.Model(core.ChatUser{}).Where("chat_id = ?", request.ChatID).Count(&members).Error; err != nil {
+				return err
+			} else if members >= int64(maxMembers) {
+				return core.ErrChatGroupFull
+			}
+
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&core.ChatUser{
+				UserID:   request.UserID,
+				ChatID:   request.ChatID,
+				JoinedAt: time.Now().Format(time.DateTime),
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+
+			joined = result.RowsAffected != 0
+		}
+
+		if joined {
+			if err := tx.Create(msg).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("id = ?", request.ID).Delete(&core.JoinRequest{}).Error
+	}); err != nil {
+		return false, err
+	}
+
+	return joined, nil
+}
@@ -0,0 +1,54 @@
+package psql
+
+import (
+	"context"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+
+	"gorm.io/gorm"
+)
+
+func (ws *WebSocket) UpdateChatUserPrefs(ctx context.Context, userId, chatId int, prefs map[string]any) error {
+	return ws.db.Model(core.ChatUser{}).Where("user_id = ? AND chat_id = ?", userId, chatId).Updates(prefs).Error
+}
+
+func (ws *WebSocket) GetPinnedChats(ctx context.Context, userId int) ([]*core.ChatUser, error) {
+	var chatUsers []*core.ChatUser
+	if err := ws.db.Where("user_id = ? AND pin_order <> 0", userId).Order("pin_order").Find(&chatUsers).Error; err != nil {
+		return nil, err
+	}
+
+	return chatUsers, nil
+}
+
+func (ws *WebSocket) ReorderPinnedChats(ctx context.Context, userId int, chatIds []int) error {
+	return ws.db.Transaction(func(tx *gorm.DB) error {
+		for i, chatId := range chatIds {
+			if err := tx.Model(core.ChatUser{}).
+				Where("user_id = ? AND chat_id = ?", userId, chatId).
+				Update("pin_order", i+1).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// MarkMessagesRead moves the read marker of the user forward, it never goes back.
+func (ws *WebSocket) MarkMessagesRead(ctx context.Context, userId, chatId, messageId int) error {
+	return ws.db.Model(core.ChatUser{}).
+		Where("user_id = ? AND chat_id = ? AND last_read_message_id < ?", userId, chatId, messageId).
+		Update("last_read_message_id", messageId).Error
+}
+
+func (ws *WebSocket) CountUnreadMessages(ctx context.Context, chatUser *core.ChatUser) (int64, error) {
+	var count int64
+	if err := ws.db.Model(core.ChatMessage{}).
+		Where("chat_id = ? AND id > ? AND user_id <> ?", chatUser.ChatID, chatUser.LastReadMessageID, chatUser.UserID).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
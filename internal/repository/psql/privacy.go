@@ -0,0 +1,100 @@
+package psql
+
+import (
+	"context"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (p *Profile) GetPrivacySettings(ctx context.Context, userId int) ([]*core.PrivacySetting, error) {
+	var settings []*core.PrivacySetting
+	if err := p.db.Where("user_id = ?", userId).Find(&settings).Error; err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (p *Profile) GetPrivacyExceptions(ctx context.Context, userId int) ([]*core.PrivacyException, error) {
+	var exceptions []*core.PrivacyException
+	if err := p.db.Where("user_id = ?", userId).Order("target_user_id").Find(&exceptions).Error; err != nil {
+		return nil, err
+	}
+
+	return exceptions, nil
+}
+
+// UpdatePrivacySetting saves the level of the setting and replaces its exceptions.
+func (p *Profile) UpdatePrivacySetting(ctx context.Context, setting *core.PrivacySetting, exceptions []*core.PrivacyException) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "setting"}},
+			DoUpdates: clause.AssignmentColumns([]string{"level"}),
+		}).Create(setting).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ? AND setting = ?", setting.UserID, setting.Setting).Delete(&core.PrivacyException{}).Error; err != nil {
+			return err
+		}
+
+		if len(exceptions) == 0 {
+			return nil
+		}
+
+		return tx.Create(&exceptions).Error
+	})
+}
+
+func (p *Profile) CheckPrivacy(ctx context.Context, ownerId, viewerId int, setting string) (bool, error) {
+	return checkPrivacy(p.db, ownerId, viewerId, setting)
+}
+
+func (ws *WebSocket) CheckPrivacy(ctx context.Context, ownerId, viewerId int, setting string) (bool, error) {
+	return checkPrivacy(ws.db, ownerId, viewerId, setting)
+}
+
+// checkPrivacy reports whether the setting of ownerId lets viewerId through.
+// Exceptions go first, then the level of the setting or its default.
+func checkPrivacy(db *gorm.DB, ownerId, viewerId int, setting string) (bool, error) {
+	if ownerId == viewerId {
+		return true, nil
+	}
+
+	var exception core.PrivacyException
+	result := db.Where("user_id = ? AND setting = ? AND target_user_id = ?", ownerId, setting, viewerId).Limit(1).Find(&exception)
+	if result.Error != nil {
+		return false, result.Error
+	} else if result.RowsAffected != 0 {
+		return exception.Allow, nil
+	}
+
+	level := core.PrivacySettings[setting]
+
+	var privacy core.PrivacySetting
+	result = db.Where("user_id = ? AND setting = ?", ownerId, setting).Limit(1).Find(&privacy)
+	if result.Error != nil {
+		return false, result.Error
+	} else if result.RowsAffected != 0 {
+		level = privacy.Level
+	}
+
+	switch level {
+	case core.PrivacyEverybody:
+		return true, nil
+	case core.PrivacyContacts:
+		var count int64
+		if err := db.Model(core.Contact{}).
+			Where("user_id = ? AND contact_user_id = ?", ownerId, viewerId).
+			Count(&count).Error; err != nil {
+			return false, err
+		}
+
+		return count != 0, nil
+	}
+
+	return false, nil
+}
@@ -39,13 +39,36 @@ func (p *Profile) UpdateProfile(ctx context.Context, user *core.User) error {
 	return p.db.Model(&core.User{}).Where("id = ?", user.ID).Updates(&user).Error
 }
 
+func (p *Profile) UpdateProfileDetails(ctx context.Context, userId int, details map[string]any) error {
+	return p.db.Model(&core.User{}).Where("id = ?", userId).Updates(details).Error
+}
+
+func (p *Profile) GetChatPeers(ctx context.Context, userId int) ([]int, error) {
+	return getChatPeers(p.db, userId)
+}
+
+// SaveAvatar appends the avatar to the end of the gallery of the user and
+// makes it the current one.
 func (p *Profile) SaveAvatar(ctx context.Context, avatar *core.UserAvatar) error {
-	return p.db.Create(&avatar).Error
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(core.UserAvatar{}).
+			Select("COALESCE(MAX(position), 0) + 1").
+			Where("user_id = ?", avatar.UserID).
+			Scan(&avatar.Position).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&avatar).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(core.User{}).Where("id = ?", avatar.UserID).Update("current_avatar_id", avatar.ID).Error
+	})
 }
 
 func (p *Profile) GetAvatars(ctx context.Context, userId int) ([]*core.UserAvatar, error) {
 	var avatars []*core.UserAvatar
-	if result := p.db.Where("user_id = ?", userId).Find(&avatars); result.RowsAffected == 0 {
+	if result := p.db.Where("user_id = ?", userId).Order("position, id").Find(&avatars); result.RowsAffected == 0 {
 		return nil, core.ErrAvatarNotFound
 	} else if result.Error != nil {
 		return nil, result.Error
@@ -63,10 +86,32 @@ func (p *Profile) GetAvatar(ctx context.Context, avatarId int) (*core.UserAvatar
 	return &avatar, nil
 }
 
+// DeleteAvatar removes the avatar, when it was the current one the user is
+// left without an explicit choice.
 func (p *Profile) DeleteAvatar(ctx context.Context, id int) error {
-	if err := p.db.Where("id = ?", id).Delete(&core.UserAvatar{}).Error; err != nil {
-		return err
-	}
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ?", id).Delete(&core.UserAvatar{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(core.User{}).Where("current_avatar_id = ?", id).Update("current_avatar_id", 0).Error
+	})
+}
+
+func (p *Profile) SetCurrentAvatar(ctx context.Context, userId, avatarId int) error {
+	return p.db.Model(core.User{}).Where("id = ?", userId).Update("current_avatar_id", avatarId).Error
+}
 
-	return nil
+func (p *Profile) ReorderAvatars(ctx context.Context, userId int, avatarIds []int) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		for i, avatarId := range avatarIds {
+			if err := tx.Model(core.UserAvatar{}).
+				Where("id = ? AND user_id = ?", avatarId, userId).
+				Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
@@ -0,0 +1,48 @@
+package psql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+func (p *Profile) GetUserByUsername(ctx context.Context, username string) (*core.User, error) {
+	var user *core.User
+	result := p.db.Where("username = ?", username).Limit(1).Find(&user)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 0 {
+		return nil, core.ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+// SearchUsers finds users whose username starts with the query, ignoring
+// case. Users who blocked the viewer are left out.
+func (p *Profile) SearchUsers(ctx context.Context, query string, viewerId, limit int) ([]*core.User, error) {
+	pattern := searchEscaper.Replace(strings.ToLower(query)) + "%"
+
+	var users []*core.User
+	if err := p.db.
+		Where("lower(username) LIKE ?", pattern).
+		Where("id <> ?", viewerId).
+		Where("NOT EXISTS (SELECT 1 FROM blocks WHERE blocks.user_id = users.id AND blocks.blocked_user_id = ?)", viewerId).
+		Order("lower(username)").
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (ws *WebSocket) GetUserByUsername(ctx context.Context, username string) (*core.User, error) {
+	var user *core.User
+	if err := ws.db.First(&user, "username = ?", username).Error; err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,124 @@
+package psql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (ws *WebSocket) CreateChatTopic(ctx context.Context, topic *core.ChatTopic) error {
+	return ws.db.Create(topic).Error
+}
+
+func (ws *WebSocket) GetChatTopic(ctx context.Context, topicId int) (*core.ChatTopic, error) {
+	var topic *core.ChatTopic
+	result := ws.db.Where("id = ?", topicId).Limit(1).Find(&topic)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 0 {
+		return nil, core.ErrTopicNotFound
+	}
+
+	return topic, nil
+}
+
+func (ws *WebSocket) GetChatTopics(ctx context.Context, chatId int) ([]*core.ChatTopic, error) {
+	var topics []*core.ChatTopic
+	if err := ws.db.Where("chat_id = ?", chatId).Order("id").Find(&topics).Error; err != nil {
+		return nil, err
+	}
+
+	return topics, nil
+}
+
+// EnableChatTopics switches the chat into topics mode. The first time it
+// happens the general topic is created, the oldest topic of the chat stays
+// the general one. Messages sent outside of topics move there, both the
+// history before the first switch and messages sent while topics were off.
+func (ws *WebSocket) EnableChatTopics(ctx context.Context, chatId int, general *core.ChatTopic) error {
+	return ws.db.Transaction(func(tx *gorm.DB) error {
+		var generalId int
+		if err := tx.Model(core.ChatTopic{}).Where("chat_id = ?", chatId).
+			Order("id").Limit(1).Pluck("id", &generalId).Error; err != nil {
+			return err
+		}
+
+		if generalId == 0 {
+			if err := tx.Create(general).Error; err != nil {
+				return err
+			}
+
+			generalId = general.ID
+		}
+
+		if err := tx.Model(core.ChatMessage{}).
+			Where("chat_id = ? AND topic_id = 0", chatId).
+			Update("topic_id", generalId).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(core.Chat{}).Where("id = ?", chatId).Update("topics_enabled", true).Error
+	})
+}
+
+func (ws *WebSocket) GetLastTopicMessage(ctx context.Context, topicId int) (*core.ChatMessage, error) {
+	var message *core.ChatMessage
+	result := ws.db.Where("topic_id = ?", topicId).Order("id DESC").Limit(1).Find(&message)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return message, nil
+}
+
+// MarkTopicRead moves the read marker of the user in the topic forward, it never goes back.
+func (ws *WebSocket) MarkTopicRead(ctx context.Context, userId, topicId, messageId int) error {
+	return ws.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "topic_id"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"last_read_message_id": gorm.Expr("GREATEST(chat_topic_reads.last_read_message_id, EXCLUDED.last_read_message_id)"),
+		}),
+	}).Create(&core.ChatTopicRead{
+		UserID:            userId,
+		TopicID:           topicId,
+		LastReadMessageID: messageId,
+	}).Error
+}
+
+func (ws *WebSocket) CountUnreadTopicMessages(ctx context.Context, userId, topicId int) (int64, error) {
+	var count int64
+	if err := ws.db.Model(core.ChatMessage{}).
+		Where("topic_id = ? AND user_id <> ?", topicId, userId).
+		Where("id > COALESCE((SELECT last_read_message_id FROM chat_topic_reads WHERE user_id = ? AND topic_id = ?), 0)", userId, topicId).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// SearchMessages looks the text up in the chat history, newest first.
+// A non zero topicId limits the search to that topic.
+func (ws *WebSocket) SearchMessages(ctx context.Context, chatId, topicId int, text string, limit int) ([]*core.ChatMessage, error) {
+	pattern := "%" + searchEscaper.Replace(text) + "%"
+
+	query := ws.db.Where("chat_id = ? AND text ILIKE ?", chatId, pattern)
+	if topicId != 0 {
+		query = query.Where("topic_id = ?", topicId)
+	}
+
+	var messages []*core.ChatMessage
+	if err := query.Order("id DESC").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+var searchEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
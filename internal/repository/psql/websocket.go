@@ -2,6 +2,7 @@ package psql
 
 import (
 	"context"
+	"time"
 
 	"github.com/Woodfyn/chat-api-backend-go/internal/core"
 	"github.com/sirupsen/logrus"
@@ -46,31 +47,43 @@ func (ws *WebSocket) GetUserByPhone(ctx context.Context, phone string) (*core.Us
 }
 
 func (ws *WebSocket) JoinChat(ctx context.Context, req *core.ChatUser) error {
-	return ws.db.Where("user_id = ? AND chat_id = ?", req.UserID, req.ChatID).FirstOrCreate(req).Error
+	return ws.db.Where("user_id = ? AND chat_id = ?", req.UserID, req.ChatID).
+		Attrs(core.ChatUser{JoinedAt: time.Now().Format(time.DateTime)}).
+		FirstOrCreate(req).Error
 }
 
-func (ws *WebSocket) GetWall(ctx context.Context, userId int) ([]*core.Chat, error) {
+func (ws *WebSocket) GetWall(ctx context.Context, userId int, archived bool) ([]*core.ChatUser, error) {
 	var chatUsers []*core.ChatUser
-	if err := ws.db.Model(core.ChatUser{}).Where("user_id = ?", userId).Find(&chatUsers).Error; err != nil {
+	if err := ws.db.Model(core.ChatUser{}).
+		Where("user_id = ? AND archived = ?", userId, archived).
+		Order("pin_order = 0, pin_order, chat_id").
+		Find(&chatUsers).Error; err != nil {
 		return nil, err
 	}
 
-	var chats []*core.Chat
-	for _, chatUser := range chatUsers {
-		var searchRoom *core.Chat
-		if err := ws.db.First(&searchRoom, chatUser.ChatID).Error; err != nil {
-			return nil, err
-		}
+	return chatUsers, nil
+}
 
-		chats = append(chats, searchRoom)
+func (ws *WebSocket) GetLastMessage(ctx context.Context, chatId int) (*core.ChatMessage, error) {
+	var message *core.ChatMessage
+	result := ws.db.Where("chat_id = ?", chatId).Order("id DESC").Limit(1).Find(&message)
+	if result.Error != nil {
+		return nil, result.Error
+	} else if result.RowsAffected == 0 {
+		return nil, nil
 	}
 
-	return chats, nil
+	return message, nil
 }
 
-func (ws *WebSocket) GetMessagesByChatId(ctx context.Context, chatId int) ([]*core.ChatMessage, error) {
+func (ws *WebSocket) GetMessagesByChatId(ctx context.Context, chatId, topicId int) ([]*core.ChatMessage, error) {
+	query := ws.db.Model(core.ChatMessage{}).Where("chat_id = ?", chatId)
+	if topicId != 0 {
+		query = query.Where("topic_id = ?", topicId)
+	}
+
 	var messages []*core.ChatMessage
-	if err := ws.db.Model(core.ChatMessage{}).Where("chat_id = ?", chatId).Find(&messages).Error; err != nil {
+	if err := query.Find(&messages).Error; err != nil {
 		return nil, err
 	}
 
@@ -151,3 +164,69 @@ func (ws *WebSocket) GetChatById(ctx context.Context, chatId int) (*core.Chat, e
 
 	return chat, nil
 }
+
+func (ws *WebSocket) GetChatUser(ctx context.Context, userId, chatId int) (*core.ChatUser, error) {
+	var chatUser *core.ChatUser
+	if err := ws.db.First(&chatUser, "user_id = ? AND chat_id = ?", userId, chatId).Error; err != nil {
+		return nil, err
+	}
+
+	return chatUser, nil
+}
+
+func (ws *WebSocket) CountUsersOnChat(ctx context.Context, chatId int) (int64, error) {
+	var count int64
+	if err := ws.db.Model(core.ChatUser{}).Where("chat_id = ?", chatId).Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (ws *WebSocket) UpdateChatGroupDescription(ctx context.Context, req *core.UpdateGroupChatDescriptionReq) error {
+	return ws.db.Model(core.Chat{}).Where("id = ?", req.ChatID).Update("description", req.Description).Error
+}
+
+func (ws *WebSocket) UpdateChatAvatar(ctx context.Context, chatId int, key string) error {
+	return ws.db.Model(core.Chat{}).Where("id = ?", chatId).Update("avatar_key", key).Error
+}
+
+func (ws *WebSocket) UpdateChatSettings(ctx context.Context, chatId int, settings map[string]any) error {
+	return ws.db.Model(core.Chat{}).Where("id = ?", chatId).Updates(settings).Error
+}
+
+// GetContactNames returns the custom names the user gave to contacts, keyed by contact id.
+func (ws *WebSocket) GetContactNames(ctx context.Context, userId int) (map[int]string, error) {
+	var contacts []*core.Contact
+	if err := ws.db.Where("user_id = ? AND name <> ''", userId).Find(&contacts).Error; err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string, len(contacts))
+	for _, contact := range contacts {
+		names[contact.ContactUserID] = contact.Name
+	}
+
+	return names, nil
+}
+
+// GetChatPeers returns the users sharing a direct chat or a group with the user.
+// Channel subscribers do not see each other and are not counted.
+func (ws *WebSocket) GetChatPeers(ctx context.Context, userId int) ([]int, error) {
+	return getChatPeers(ws.db, userId)
+}
+
+// getChatPeers is shared by the repositories that notify chat peers.
+func getChatPeers(db *gorm.DB, userId int) ([]int, error) {
+	var peerIds []int
+	if err := db.Model(core.ChatUser{}).
+		Distinct("chat_users.user_id").
+		Joins("JOIN chat_users AS mine ON mine.chat_id = chat_users.chat_id").
+		Joins("JOIN chats ON chats.id = chat_users.chat_id").
+		Where("mine.user_id = ? AND chat_users.user_id <> ? AND chats.type <> ?", userId, userId, core.ChannelChatType).
+		Pluck("chat_users.user_id", &peerIds).Error; err != nil {
+		return nil, err
+	}
+
+	return peerIds, nil
+}
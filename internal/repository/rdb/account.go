@@ -0,0 +1,146 @@
+package rdb
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-redis/redis"
+)
+
+type Account struct {
+	redis *redis.Client
+
+	ttl time.Duration
+
+	log *logrus.Logger
+}
+
+func NewAccount(redis *redis.Client, ttl time.Duration, log *logrus.Logger) *Account {
+	return &Account{
+		redis: redis,
+
+		ttl: ttl,
+
+		log: log,
+	}
+}
+
+func (a *Account) SetDeletionCode(ctx context.Context, userId int, code string) error {
+	return a.redis.Set(deletionCodeKey(userId), code, a.ttl).Err()
+}
+
+// CheckDeletionCode reports whether the code matches the one sent to the
+// user, a matching code is used up. Every attempt is counted before the
+// code is compared, the code is removed after maxAttempts wrong ones and
+// later codes fail until the count expires.
+func (a *Account) CheckDeletionCode(ctx context.Context, userId int, code string, maxAttempts int) (bool, error) {
+	stored, err := a.redis.Get(deletionCodeKey(userId)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	attempts, err := incrWithin(a.redis, deletionAttemptsKey(userId), a.ttl)
+	if err != nil {
+		return false, err
+	}
+
+	if attempts > maxAttempts || subtle.ConstantTimeCompare([]byte(stored), []byte(code)) != 1 {
+		if attempts >= maxAttempts {
+			if err := a.redis.Del(deletionCodeKey(userId)).Err(); err != nil {
+				return false, err
+			}
+		}
+
+		return false, nil
+	}
+
+	if err := a.redis.Del(deletionCodeKey(userId), deletionAttemptsKey(userId)).Err(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (a *Account) SetPhoneChange(ctx context.Context, userId int, change *core.PhoneChange) error {
+	data, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	return a.redis.Set(phoneChangeKey(userId), data, a.ttl).Err()
+}
+
+func (a *Account) GetPhoneChange(ctx context.Context, userId int) (*core.PhoneChange, error) {
+	data, err := a.redis.Get(phoneChangeKey(userId)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, core.ErrPhoneChangeNotFound
+		}
+
+		return nil, err
+	}
+
+	var change *core.PhoneChange
+	if err := json.Unmarshal(data, &change); err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+func (a *Account) DeletePhoneChange(ctx context.Context, userId int) error {
+	return a.redis.Del(phoneChangeKey(userId)).Err()
+}
+
+// AddPhoneChangeSend reserves sending phone change codes for the user. It
+// returns false when the previous codes were sent less than interval ago and
+// otherwise the requests since the first one of the window.
+func (a *Account) AddPhoneChangeSend(ctx context.Context, userId int, interval, window time.Duration) (bool, int, error) {
+	ok, err := a.redis.SetNX(fmt.Sprintf("account:phone:cooldown:%d", userId), 1, interval).Result()
+	if err != nil || !ok {
+		return false, 0, err
+	}
+
+	sent, err := incrWithin(a.redis, fmt.Sprintf("account:phone:sent:%d", userId), window)
+	if err != nil {
+		return false, 0, err
+	}
+
+	return true, sent, nil
+}
+
+// AddPhoneChangeAttempt counts a confirmation attempt of the user. The count
+// outlives the pending change, so requesting new codes does not reset it.
+func (a *Account) AddPhoneChangeAttempt(ctx context.Context, userId int, window time.Duration) (int, error) {
+	return incrWithin(a.redis, phoneChangeAttemptsKey(userId), window)
+}
+
+func (a *Account) ResetPhoneChangeAttempts(ctx context.Context, userId int) error {
+	return a.redis.Del(phoneChangeAttemptsKey(userId)).Err()
+}
+
+func phoneChangeKey(userId int) string {
+	return fmt.Sprintf("account:phone:%d", userId)
+}
+
+func phoneChangeAttemptsKey(userId int) string {
+	return fmt.Sprintf("account:phone:attempts:%d", userId)
+}
+
+func deletionCodeKey(userId int) string {
+	return fmt.Sprintf("account:delete:%d", userId)
+}
+
+func deletionAttemptsKey(userId int) string {
+	return fmt.Sprintf("account:delete:attempts:%d", userId)
+}
@@ -0,0 +1,103 @@
+package rdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-redis/redis"
+)
+
+// Auth keeps the revoked access tokens. Every entry expires once the tokens
+// it revokes would have expired anyway.
+type Auth struct {
+	redis *redis.Client
+
+	log *logrus.Logger
+}
+
+func NewAuth(redis *redis.Client, log *logrus.Logger) *Auth {
+	return &Auth{
+		redis: redis,
+
+		log: log,
+	}
+}
+
+func (a *Auth) DenyToken(ctx context.Context, tokenId string, ttl time.Duration) error {
+	return a.redis.Set(deniedTokenKey(tokenId), 1, ttl).Err()
+}
+
+// DenySessions revokes every access token issued for the sessions.
+func (a *Auth) DenySessions(ctx context.Context, ttl time.Duration, sessionIds ...int) error {
+	pipe := a.redis.TxPipeline()
+	for _, sessionId := range sessionIds {
+		pipe.Set(deniedSessionKey(sessionId), 1, ttl)
+	}
+
+	_, err := pipe.Exec()
+	return err
+}
+
+// SetNotValidBefore revokes every access token of the user issued up to the
+// given moment.
+func (a *Auth) SetNotValidBefore(ctx context.Context, userId int, at time.Time, ttl time.Duration) error {
+	return a.redis.Set(notValidBeforeKey(userId), at.Unix(), ttl).Err()
+}
+
+// GetRevocation reports in one round trip whether the token or its session
+// is denied and returns the moment the tokens of the user are not valid
+// before, the zero time when they were never revoked. An empty tokenId or
+// a zero sessionId is not looked up.
+func (a *Auth) GetRevocation(ctx context.Context, tokenId string, sessionId, userId int) (bool, time.Time, error) {
+	keys := []string{}
+	if tokenId != "" {
+		keys = append(keys, deniedTokenKey(tokenId))
+	}
+
+	if sessionId != 0 {
+		keys = append(keys, deniedSessionKey(sessionId))
+	}
+
+	var denied *redis.IntCmd
+	var notValidBefore *redis.StringCmd
+	if _, err := a.redis.Pipelined(func(pipe redis.Pipeliner) error {
+		if len(keys) != 0 {
+			denied = pipe.Exists(keys...)
+		}
+
+		notValidBefore = pipe.Get(notValidBeforeKey(userId))
+		return nil
+	}); err != nil && err != redis.Nil {
+		return false, time.Time{}, err
+	}
+
+	if denied != nil && denied.Val() != 0 {
+		return true, time.Time{}, nil
+	}
+
+	unix, err := notValidBefore.Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return false, time.Time{}, nil
+		}
+
+		return false, time.Time{}, err
+	}
+
+	return false, time.Unix(unix, 0), nil
+}
+
+func deniedTokenKey(tokenId string) string {
+	return fmt.Sprintf("auth:deny:token:%s", tokenId)
+}
+
+func deniedSessionKey(sessionId int) string {
+	return fmt.Sprintf("auth:deny:session:%d", sessionId)
+}
+
+func notValidBeforeKey(userId int) string {
+	return fmt.Sprintf("auth:nvb:%d", userId)
+}
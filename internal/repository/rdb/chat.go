@@ -0,0 +1,59 @@
+package rdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-redis/redis"
+)
+
+type Chat struct {
+	redis *redis.Client
+
+	log *logrus.Logger
+}
+
+func NewChat(redis *redis.Client, log *logrus.Logger) *Chat {
+	return &Chat{
+		redis: redis,
+
+		log: log,
+	}
+}
+
+// SetCooldown starts the slow mode cooldown of the user in the chat.
+// When a cooldown is already running it is left untouched and its
+// remaining time is returned instead.
+func (c *Chat) SetCooldown(ctx context.Context, chatId, userId int, ttl time.Duration) (time.Duration, error) {
+	key := cooldownKey(chatId, userId)
+
+	ok, err := c.redis.SetNX(key, 1, ttl).Result()
+	if err != nil {
+		return 0, err
+	} else if ok {
+		return 0, nil
+	}
+
+	remaining, err := c.redis.TTL(key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// the key expired between the two calls
+	if remaining <= 0 {
+		return time.Second, nil
+	}
+
+	return remaining, nil
+}
+
+func (c *Chat) ClearCooldown(ctx context.Context, chatId, userId int) error {
+	return c.redis.Del(cooldownKey(chatId, userId)).Err()
+}
+
+func cooldownKey(chatId, userId int) string {
+	return fmt.Sprintf("slowmode:%d:%d", chatId, userId)
+}
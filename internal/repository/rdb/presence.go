@@ -0,0 +1,178 @@
+package rdb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+
+	"github.com/go-redis/redis"
+)
+
+// SetOnline registers a new stream of the user. Streams are counted so a
+// user with connections on several instances goes offline only after the
+// last one is closed. Live streams refresh the heartbeat of the user, a
+// user whose heartbeat is older than ttl is taken offline by ExpireOnline,
+// so streams of a crashed instance do not keep the user online. It reports
+// whether the user has just come online.
+func (c *Chat) SetOnline(ctx context.Context, userId int, ttl time.Duration) (bool, error) {
+	var streams *redis.IntCmd
+	if _, err := c.redis.TxPipelined(func(pipe redis.Pipeliner) error {
+		streams = pipe.Incr(onlineKey(userId))
+		setHeartbeat(pipe, userId, ttl)
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	return streams.Val() == 1, nil
+}
+
+// RefreshOnline extends the heartbeat of the user while a stream of the
+// user is alive.
+func (c *Chat) RefreshOnline(ctx context.Context, userId int, ttl time.Duration) error {
+	_, err := c.redis.TxPipelined(func(pipe redis.Pipeliner) error {
+		setHeartbeat(pipe, userId, ttl)
+		return nil
+	})
+	return err
+}
+
+// setHeartbeat keeps the counter a little longer than the heartbeat, so
+// ExpireOnline finds the user before the counter disappears on its own.
+func setHeartbeat(pipe redis.Pipeliner, userId int, ttl time.Duration) {
+	pipe.Expire(onlineKey(userId), 2*ttl)
+	pipe.ZAdd(heartbeatsKey, redis.Z{
+		Score:  float64(time.Now().Add(ttl).Unix()),
+		Member: userId,
+	})
+}
+
+// expireOnlineScript takes the user offline only if no stream refreshed the
+// heartbeat since it was read, one instance wins when several sweep at once.
+var expireOnlineScript = redis.NewScript(`
+local deadline = redis.call("ZSCORE", KEYS[1], ARGV[1])
+if not deadline or tonumber(deadline) > tonumber(ARGV[2]) then
+	return 0
+end
+redis.call("ZREM", KEYS[1], ARGV[1])
+redis.call("DEL", KEYS[2])
+redis.call("SET", KEYS[3], ARGV[3])
+return 1
+`)
+
+// ExpireOnline takes offline the users whose heartbeat is older than ttl,
+// their last seen time is their last heartbeat. It returns the presence of
+// the users it took offline.
+func (c *Chat) ExpireOnline(ctx context.Context, now time.Time, ttl time.Duration) ([]*core.PresenceResp, error) {
+	expired, err := c.redis.ZRangeByScoreWithScores(heartbeatsKey, redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var presence []*core.PresenceResp
+	for _, heartbeat := range expired {
+		userId, err := strconv.Atoi(fmt.Sprint(heartbeat.Member))
+		if err != nil {
+			return nil, err
+		}
+
+		lastSeen := time.Unix(int64(heartbeat.Score), 0).Add(-ttl).Format(time.DateTime)
+
+		taken, err := expireOnlineScript.Run(c.redis,
+			[]string{heartbeatsKey, onlineKey(userId), lastSeenKey(userId)},
+			userId, now.Unix(), lastSeen).Int()
+		if err != nil {
+			return nil, err
+		} else if taken == 0 {
+			continue
+		}
+
+		presence = append(presence, &core.PresenceResp{
+			UserID:   userId,
+			LastSeen: lastSeen,
+		})
+	}
+
+	return presence, nil
+}
+
+// SetOffline unregisters a stream of the user and stores the last seen
+// time once the last stream is gone. It reports whether the user has
+// just gone offline.
+func (c *Chat) SetOffline(ctx context.Context, userId int, lastSeen time.Time) (bool, error) {
+	streams, err := c.redis.Decr(onlineKey(userId)).Result()
+	if err != nil {
+		return false, err
+	}
+
+	if streams > 0 {
+		return false, nil
+	}
+
+	if _, err := c.redis.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.Del(onlineKey(userId))
+		pipe.ZRem(heartbeatsKey, userId)
+		pipe.Set(lastSeenKey(userId), lastSeen.Format(time.DateTime), 0)
+		return nil
+	}); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (c *Chat) GetPresence(ctx context.Context, userIds []int) ([]*core.PresenceResp, error) {
+	online := make([]*redis.StringCmd, len(userIds))
+	lastSeen := make([]*redis.StringCmd, len(userIds))
+
+	if _, err := c.redis.Pipelined(func(pipe redis.Pipeliner) error {
+		for i, userId := range userIds {
+			online[i] = pipe.Get(onlineKey(userId))
+			lastSeen[i] = pipe.Get(lastSeenKey(userId))
+		}
+		return nil
+	}); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	presence := make([]*core.PresenceResp, 0, len(userIds))
+	for i, userId := range userIds {
+		streams, err := online[i].Int64()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+
+		seen, err := lastSeen[i].Result()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+
+		if streams > 0 {
+			seen = ""
+		}
+
+		presence = append(presence, &core.PresenceResp{
+			UserID:   userId,
+			Online:   streams > 0,
+			LastSeen: seen,
+		})
+	}
+
+	return presence, nil
+}
+
+const heartbeatsKey = "presence:heartbeats"
+
+func onlineKey(userId int) string {
+	return fmt.Sprintf("presence:online:%d", userId)
+}
+
+func lastSeenKey(userId int) string {
+	return fmt.Sprintf("presence:lastseen:%d", userId)
+}
@@ -0,0 +1,38 @@
+package rdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/go-redis/redis"
+)
+
+type Profile struct {
+	redis *redis.Client
+
+	log *logrus.Logger
+}
+
+func NewProfile(redis *redis.Client, log *logrus.Logger) *Profile {
+	return &Profile{
+		redis: redis,
+
+		log: log,
+	}
+}
+
+// AllowSearch counts a user search in the current window and reports
+// whether the user is still within the limit.
+func (p *Profile) AllowSearch(ctx context.Context, userId, limit int, window time.Duration) (bool, error) {
+	key := fmt.Sprintf("ratelimit:usersearch:%d", userId)
+
+	count, err := incrWithin(p.redis, key, window)
+	if err != nil {
+		return false, err
+	}
+
+	return count <= limit, nil
+}
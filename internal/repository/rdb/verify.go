@@ -2,6 +2,9 @@ package rdb
 
 import (
 	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/Woodfyn/chat-api-backend-go/internal/core"
@@ -28,23 +31,144 @@ func NewVerife(redis *redis.Client, ttl time.Duration, log *logrus.Logger) *Veri
 	}
 }
 
-func (v *Verify) SetCode(ctx context.Context, id int, code string) error {
-	return v.redis.Set(code, id, v.ttl).Err()
+// SetCode stores the login code of the phone, a new code replaces the
+// previous one.
+func (v *Verify) SetCode(ctx context.Context, phone string, userId int, code string) error {
+	data, err := json.Marshal(&core.VerifyCode{
+		UserID: userId,
+		Code:   code,
+	})
+	if err != nil {
+		return err
+	}
+
+	return v.redis.Set(codeKey(phone), data, v.ttl).Err()
 }
 
-func (v *Verify) Verify(ctx context.Context, code string) (string, error) {
-	id, err := v.redis.Get(code).Result()
+// Verify returns the id of the user the code was sent to, a matching code
+// is used up. Of parallel requests with the right code only the one that
+// consumes the code succeeds.
+func (v *Verify) Verify(ctx context.Context, phone, code string) (int, error) {
+	data, err := v.redis.Get(codeKey(phone)).Bytes()
 	if err != nil {
 		if err == redis.Nil {
-			return "", core.ErrCodeNotFound
+			return 0, core.ErrCodeNotFound
 		}
 
-		return "", err
+		return 0, err
+	}
+
+	var stored *core.VerifyCode
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return 0, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(stored.Code), []byte(code)) != 1 {
+		return 0, core.ErrCodeNotFound
+	}
+
+	consumed, err := consumeScript.Run(v.redis, []string{codeKey(phone)}, data).Int()
+	if err != nil {
+		return 0, err
+	} else if consumed == 0 {
+		return 0, core.ErrCodeNotFound
+	}
+
+	return stored.UserID, nil
+}
+
+// GetLockout returns how long the key stays locked, zero when it is not.
+func (v *Verify) GetLockout(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := v.redis.TTL(lockoutKey(key)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	return max(ttl, 0), nil
+}
+
+// Lock locks the key for duration and reports false when the key is
+// already locked, so only one of several parallel attempts gets the lock.
+func (v *Verify) Lock(ctx context.Context, key string, duration time.Duration) (bool, error) {
+	return v.redis.SetNX(lockoutKey(key), 1, duration).Result()
+}
+
+// AddAttempt counts an attempt of the key before its code is compared and
+// returns the attempts since the first one of the window.
+func (v *Verify) AddAttempt(ctx context.Context, key string, window time.Duration) (int, error) {
+	return incrWithin(v.redis, attemptsKey(key), window)
+}
+
+// RemoveAttempt takes back an attempt that turned out to be right.
+func (v *Verify) RemoveAttempt(ctx context.Context, key string) error {
+	return decrIfExistsScript.Run(v.redis, []string{attemptsKey(key)}).Err()
+}
+
+func (v *Verify) ResetAttempts(ctx context.Context, key string) error {
+	return v.redis.Del(attemptsKey(key), lockoutKey(key)).Err()
+}
+
+// AddCodeSend reserves sending a code to the phone. It returns false when
+// the previous code was sent less than interval ago and otherwise the codes
+// sent since the first one of the window.
+func (v *Verify) AddCodeSend(ctx context.Context, phone string, interval, window time.Duration) (bool, int, error) {
+	ok, err := v.redis.SetNX(fmt.Sprintf("verify:cooldown:%s", phone), 1, interval).Result()
+	if err != nil || !ok {
+		return false, 0, err
 	}
 
-	if err := v.redis.Del(code).Err(); err != nil {
-		return "", err
+	sent, err := incrWithin(v.redis, fmt.Sprintf("verify:sent:%s", phone), window)
+	if err != nil {
+		return false, 0, err
 	}
 
-	return id, nil
+	return true, sent, nil
+}
+
+var (
+	// incrWithinScript increments the counter and starts its window on the
+	// first increment in one step, so a counter never lives without a TTL.
+	incrWithinScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
+	// consumeScript deletes the key only while it still holds the value
+	// that was read, a code replaced in between is kept.
+	consumeScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+	// decrIfExistsScript does not bring back an expired counter without
+	// a TTL.
+	decrIfExistsScript = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return redis.call("DECR", KEYS[1])
+end
+return 0
+`)
+)
+
+// incrWithin increments the counter, the counter expires window after its
+// first increment.
+func incrWithin(client *redis.Client, key string, window time.Duration) (int, error) {
+	return incrWithinScript.Run(client, []string{key}, window.Milliseconds()).Int()
+}
+
+func codeKey(phone string) string {
+	return fmt.Sprintf("verify:code:%s", phone)
+}
+
+func attemptsKey(key string) string {
+	return fmt.Sprintf("verify:attempts:%s", key)
+}
+
+func lockoutKey(key string) string {
+	return fmt.Sprintf("verify:lockout:%s", key)
 }
@@ -0,0 +1,91 @@
+package rdb_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+	"github.com/Woodfyn/chat-api-backend-go/internal/repository/rdb"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis"
+	"github.com/sirupsen/logrus"
+)
+
+func newVerify(t *testing.T) *rdb.Verify {
+	server := miniredis.RunT(t)
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return rdb.NewVerife(client, time.Minute, logrus.New())
+}
+
+func TestVerifyConsumesCodeOnce(t *testing.T) {
+	ctx := context.Background()
+	verify := newVerify(t)
+
+	if err := verify.SetCode(ctx, "+380671234567", 7, "123456"); err != nil {
+		t.Fatalf("SetCode() error = %v", err)
+	}
+
+	const parallel = 10
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		passed int
+	)
+
+	start := make(chan struct{})
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+
+			userId, err := verify.Verify(ctx, "+380671234567", "123456")
+			if err != nil {
+				if !errors.Is(err, core.ErrCodeNotFound) {
+					t.Errorf("Verify() error = %v", err)
+				}
+
+				return
+			}
+
+			if userId != 7 {
+				t.Errorf("Verify() = %d, want 7", userId)
+			}
+
+			mu.Lock()
+			passed++
+			mu.Unlock()
+		}()
+	}
+
+	close(start)
+	wg.Wait()
+
+	if passed != 1 {
+		t.Errorf("Verify() passed %d times, want once", passed)
+	}
+}
+
+func TestVerifyKeepsCodeOnMismatch(t *testing.T) {
+	ctx := context.Background()
+	verify := newVerify(t)
+
+	if err := verify.SetCode(ctx, "+380671234567", 7, "123456"); err != nil {
+		t.Fatalf("SetCode() error = %v", err)
+	}
+
+	if _, err := verify.Verify(ctx, "+380671234567", "654321"); !errors.Is(err, core.ErrCodeNotFound) {
+		t.Errorf("Verify() with a wrong code error = %v, want %v", err, core.ErrCodeNotFound)
+	}
+
+	if _, err := verify.Verify(ctx, "+380671234567", "123456"); err != nil {
+		t.Errorf("Verify() after a wrong code error = %v", err)
+	}
+}
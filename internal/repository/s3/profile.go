@@ -2,7 +2,7 @@ package s3
 
 import (
 	"context"
-	"mime/multipart"
+	"io"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -31,15 +31,16 @@ func NewProfile(s3 *s3.Client, presign *s3.PresignClient, bucketName string, log
 	}
 }
 
-func (a *Profile) UploadAvatar(ctx context.Context, file multipart.File, key string) error {
+func (a *Profile) UploadAvatar(ctx context.Context, file io.Reader, key string) error {
 	if file == nil {
 		return nil
 	}
 
 	if _, err := a.s3.PutObject(ctx, &s3.PutObjectInput{
-		Bucket: aws.String(a.bucketName),
-		Key:    aws.String(key),
-		Body:   file,
+		Bucket:      aws.String(a.bucketName),
+		Key:         aws.String(key),
+		Body:        file,
+		ContentType: aws.String("image/jpeg"),
 	}); err != nil {
 		return err
 	}
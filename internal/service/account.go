@@ -0,0 +1,390 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+	"github.com/Woodfyn/chat-api-backend-go/pkg/phone"
+	"github.com/Woodfyn/chat-api-backend-go/pkg/verife"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ACCOUNT_DELETION_GRACE = 7 * 24 * time.Hour
+	ACCOUNT_PURGE_INTERVAL = time.Hour
+
+	// PHONE_CHANGE_ATTEMPTS is how many confirmations a user may try per
+	// PHONE_CHANGE_ATTEMPTS_WINDOW, requesting new codes does not reset them.
+	PHONE_CHANGE_ATTEMPTS        = 5
+	PHONE_CHANGE_ATTEMPTS_WINDOW = 24 * time.Hour
+
+	// DELETION_ATTEMPTS is how many wrong deletion codes are accepted before
+	// the code is dropped.
+	DELETION_ATTEMPTS = 5
+)
+
+type AccountRepositoryPSQL interface {
+	GetUserById(ctx context.Context, userId int) (*core.User, error)
+	ScheduleDeletion(ctx context.Context, userId int, scheduledAt string) error
+	CancelDeletion(ctx context.Context, userId int) error
+	GetUsersDueForDeletion(ctx context.Context, now string) ([]*core.User, error)
+	GetAvatars(ctx context.Context, userId int) ([]*core.UserAvatar, error)
+	PurgeUser(ctx context.Context, userId int) ([]string, error)
+	GetProfileExport(ctx context.Context, userId int) (*core.User, error)
+	GetSessions(ctx context.Context, userId int) ([]*core.SessionExport, error)
+	GetMemberships(ctx context.Context, userId int) ([]*core.MembershipExport, error)
+	GetUserMessages(ctx context.Context, userId int) ([]*core.ChatMessage, error)
+	IsPhoneTaken(ctx context.Context, phone string) (bool, error)
+	ChangePhone(ctx context.Context, userId, sessionId int, phone, phoneHash string) ([]int, error)
+	GetContactHolders(ctx context.Context, userId int) ([]int, error)
+	CheckPrivacy(ctx context.Context, ownerId, viewerId int, setting string) (bool, error)
+}
+
+type AccountRepositoryREDIS interface {
+	SetDeletionCode(ctx context.Context, userId int, code string) error
+	CheckDeletionCode(ctx context.Context, userId int, code string, maxAttempts int) (bool, error)
+	SetPhoneChange(ctx context.Context, userId int, change *core.PhoneChange) error
+	GetPhoneChange(ctx context.Context, userId int) (*core.PhoneChange, error)
+	DeletePhoneChange(ctx context.Context, userId int) error
+	AddPhoneChangeSend(ctx context.Context, userId int, interval, window time.Duration) (bool, int, error)
+	AddPhoneChangeAttempt(ctx context.Context, userId int, window time.Duration) (int, error)
+	ResetPhoneChangeAttempts(ctx context.Context, userId int) error
+}
+
+type AccountRepositoryS3 interface {
+	DeleteAvatar(ctx context.Context, key string) error
+}
+
+type Account struct {
+	psqlRepo   AccountRepositoryPSQL
+	redisRepo  AccountRepositoryREDIS
+	s3Repo     AccountRepositoryS3
+	s3ChatRepo AccountRepositoryS3
+	twilioRepo VerifyRepositoryTWILIO
+
+	avatarKeySalt string
+	phoneSalt     string
+
+	log *logrus.Logger
+}
+
+func NewAccount(psqlRepo AccountRepositoryPSQL, redisRepo AccountRepositoryREDIS, s3Repo, s3ChatRepo AccountRepositoryS3, twilioRepo VerifyRepositoryTWILIO, avatarKeySalt, phoneSalt string, log *logrus.Logger) *Account {
+	return &Account{
+		psqlRepo:   psqlRepo,
+		redisRepo:  redisRepo,
+		s3Repo:     s3Repo,
+		s3ChatRepo: s3ChatRepo,
+		twilioRepo: twilioRepo,
+
+		avatarKeySalt: avatarKeySalt,
+		phoneSalt:     phoneSalt,
+
+		log: log,
+	}
+}
+
+// RequestDeletion sends the code confirming the account deletion to the
+// phone of the user.
+func (a *Account) RequestDeletion(ctx context.Context, userId int) error {
+	user, err := a.psqlRepo.GetUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if user.DeletionScheduledAt != "" {
+		return core.ErrDeletionAlreadyScheduled
+	}
+
+	code, err := verife.GenereteCode()
+	if err != nil {
+		return err
+	}
+
+	if err := a.redisRepo.SetDeletionCode(ctx, userId, code); err != nil {
+		return err
+	}
+
+	return a.twilioRepo.SendCode(ctx, code, user.Phone)
+}
+
+// ConfirmDeletion schedules the deletion after ACCOUNT_DELETION_GRACE, until
+// then the user may cancel it.
+func (a *Account) ConfirmDeletion(ctx context.Context, req *core.ConfirmAccountDeletionReq, userId int) (*core.AccountDeletionResp, error) {
+	ok, err := a.redisRepo.CheckDeletionCode(ctx, userId, req.Code, DELETION_ATTEMPTS)
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, core.ErrCodeNotFound
+	}
+
+	scheduledAt := time.Now().Add(ACCOUNT_DELETION_GRACE).Format(time.DateTime)
+
+	if err := a.psqlRepo.ScheduleDeletion(ctx, userId, scheduledAt); err != nil {
+		return nil, err
+	}
+
+	return &core.AccountDeletionResp{
+		DeletionScheduledAt: scheduledAt,
+	}, nil
+}
+
+func (a *Account) CancelDeletion(ctx context.Context, userId int) error {
+	return a.psqlRepo.CancelDeletion(ctx, userId)
+}
+
+// RunPurge deletes the accounts whose grace period is over every interval
+// until the context is done.
+func (a *Account) RunPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := a.PurgeDeletedAccounts(ctx); err != nil {
+			a.log.Error("Error when purging deleted accounts: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *Account) PurgeDeletedAccounts(ctx context.Context) error {
+	users, err := a.psqlRepo.GetUsersDueForDeletion(ctx, time.Now().Format(time.DateTime))
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if err := a.purgeAccount(ctx, user.ID); err != nil {
+			a.log.Errorf("Error when purging account %d: %v", user.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// purgeAccount removes the avatars from S3 before the database rows, so an
+// account failing half way is picked up again on the next run.
+func (a *Account) purgeAccount(ctx context.Context, userId int) error {
+	avatars, err := a.psqlRepo.GetAvatars(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	for _, avatar := range avatars {
+		for _, key := range avatarKeys(a.avatarKeySalt, avatar) {
+			if err := a.s3Repo.DeleteAvatar(ctx, key); err != nil {
+				return err
+			}
+		}
+	}
+
+	chatAvatarKeys, err := a.psqlRepo.PurgeUser(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range chatAvatarKeys {
+		if err := a.s3ChatRepo.DeleteAvatar(ctx, key); err != nil {
+			a.log.Error("Error when deleting chat avatar: ", err)
+		}
+	}
+
+	return nil
+}
+
+// ExportData packs everything the user created into a zip archive with one
+// json file per kind of data.
+func (a *Account) ExportData(ctx context.Context, userId int) ([]byte, error) {
+	profile, err := a.psqlRepo.GetProfileExport(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := a.psqlRepo.GetSessions(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	memberships, err := a.psqlRepo.GetMemberships(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, err := a.psqlRepo.GetUserMessages(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	for _, file := range []struct {
+		name string
+		data any
+	}{
+		{"profile.json", profile},
+		{"sessions.json", sessions},
+		{"memberships.json", memberships},
+		{"messages.json", messages},
+	} {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		if err := encoder.Encode(file.data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// RequestPhoneChange sends a code to the new phone and, when the user asks
+// for it, another one to the current phone. The change waits for
+// ConfirmPhoneChange.
+func (a *Account) RequestPhoneChange(ctx context.Context, req *core.ChangePhoneReq, userId int) error {
+	user, err := a.psqlRepo.GetUserById(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if user.Phone == req.Phone {
+		return core.ErrSamePhone
+	}
+
+	taken, err := a.psqlRepo.IsPhoneTaken(ctx, req.Phone)
+	if err != nil {
+		return err
+	} else if taken {
+		return core.ErrThisCredIsAlready
+	}
+
+	ok, sent, err := a.redisRepo.AddPhoneChangeSend(ctx, userId, CODE_RESEND_INTERVAL, CODE_RESEND_WINDOW)
+	if err != nil {
+		return err
+	} else if !ok || sent > CODE_RESEND_LIMIT {
+		return core.ErrCodeSentTooOften
+	}
+
+	change := &core.PhoneChange{
+		Phone: req.Phone,
+	}
+
+	if change.NewCode, err = verife.GenereteCode(); err != nil {
+		return err
+	}
+
+	if req.VerifyOld {
+		if change.OldCode, err = verife.GenereteCode(); err != nil {
+			return err
+		}
+	}
+
+	if err := a.redisRepo.SetPhoneChange(ctx, userId, change); err != nil {
+		return err
+	}
+
+	if err := a.twilioRepo.SendCode(ctx, change.NewCode, change.Phone); err != nil {
+		return err
+	}
+
+	if change.OldCode != "" {
+		return a.twilioRepo.SendCode(ctx, change.OldCode, user.Phone)
+	}
+
+	return nil
+}
+
+// ConfirmPhoneChange swaps the phone once every requested code matches and
+// logs out all other sessions. It also returns the ids of the removed sessions.
+func (a *Account) ConfirmPhoneChange(ctx context.Context, req *core.ConfirmPhoneChangeReq, userId, sessionId int) (*core.PhoneChangedResp, []int, error) {
+	change, err := a.redisRepo.GetPhoneChange(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// The attempt is counted before the codes are compared, so parallel
+	// requests cannot get past the limit.
+	attempts, err := a.redisRepo.AddPhoneChangeAttempt(ctx, userId, PHONE_CHANGE_ATTEMPTS_WINDOW)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if attempts > PHONE_CHANGE_ATTEMPTS {
+		if err := a.redisRepo.DeletePhoneChange(ctx, userId); err != nil {
+			return nil, nil, err
+		}
+
+		return nil, nil, core.ErrTooManyAttempts
+	}
+
+	newMatch := subtle.ConstantTimeCompare([]byte(req.NewCode), []byte(change.NewCode))
+	oldMatch := subtle.ConstantTimeCompare([]byte(req.OldCode), []byte(change.OldCode))
+	if newMatch&oldMatch != 1 {
+		if attempts == PHONE_CHANGE_ATTEMPTS {
+			if err := a.redisRepo.DeletePhoneChange(ctx, userId); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		return nil, nil, core.ErrInvalidPhoneCode
+	}
+
+	revoked, err := a.psqlRepo.ChangePhone(ctx, userId, sessionId, change.Phone, phone.Hash(a.phoneSalt, change.Phone))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := a.redisRepo.DeletePhoneChange(ctx, userId); err != nil {
+		a.log.Error("Error when deleting phone change: ", err)
+	}
+
+	if err := a.redisRepo.ResetPhoneChangeAttempts(ctx, userId); err != nil {
+		a.log.Error("Error when resetting phone change attempts: ", err)
+	}
+
+	return &core.PhoneChangedResp{
+		UserID: userId,
+		Phone:  change.Phone,
+	}, revoked, nil
+}
+
+// GetPhoneChangeWatchers returns the users keeping the user in their
+// contacts who are allowed to see the phone of the user.
+func (a *Account) GetPhoneChangeWatchers(ctx context.Context, userId int) ([]int, error) {
+	holderIds, err := a.psqlRepo.GetContactHolders(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	watcherIds := make([]int, 0, len(holderIds))
+	for _, holderId := range holderIds {
+		allowed, err := a.psqlRepo.CheckPrivacy(ctx, userId, holderId, core.PrivacyPhone)
+		if err != nil {
+			return nil, err
+		}
+
+		if allowed {
+			watcherIds = append(watcherIds, holderId)
+		}
+	}
+
+	return watcherIds, nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+// LeaveChatGroupAsAdmin lets the admin leave the group by handing it over
+// to a successor. The returned message is nil when the group was empty
+// and has been deleted.
+func (ws *WebSocket) LeaveChatGroupAsAdmin(ctx context.Context, req *core.LeaveChatGroupAdminReq, userId int) (*core.ChatAdminLeftResp, *core.ChatMessage, error) {
+	chat, err := ws.psqlRepo.GetChatById(ctx, req.ChatID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if chat.Type != core.GroupChatType {
+		return nil, nil, core.ErrInvalideChatID
+	}
+
+	resp, err := ws.psqlRepo.LeaveChatAsAdmin(ctx, chat.ID, userId, req.SuccessorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if resp.ChatDeleted {
+		if chat.AvatarKey != "" {
+			if err := ws.s3Repo.DeleteAvatar(ctx, chat.AvatarKey); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		return resp, nil, nil
+	}
+
+	user, err := ws.psqlRepo.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	successor, err := ws.psqlRepo.GetUserById(ctx, resp.NewAdminID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msg := &core.ChatMessage{
+		Username:  user.Username,
+		UserID:    user.ID,
+		ChatID:    chat.ID,
+		Text:      fmt.Sprintf("%s left the chat, %s is new chat admin", user.Username, successor.Username),
+		CreatedAt: time.Now().Format(time.DateTime),
+	}
+
+	if err = ws.psqlRepo.SaveMessage(ctx, msg); err != nil {
+		return nil, nil, err
+	}
+
+	return resp, msg, nil
+}
@@ -7,23 +7,64 @@ import (
 	"time"
 
 	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+	"github.com/Woodfyn/chat-api-backend-go/pkg/phone"
 	"github.com/Woodfyn/chat-api-backend-go/pkg/token"
 	"github.com/Woodfyn/chat-api-backend-go/pkg/verife"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// VERIFY_MAX_ATTEMPTS codes within VERIFY_FAILURE_WINDOW lock the phone
+	// or the IP, every further attempt doubles the lockout starting from
+	// VERIFY_LOCKOUT up to VERIFY_MAX_LOCKOUT.
+	VERIFY_MAX_ATTEMPTS   = 5
+	VERIFY_FAILURE_WINDOW = 24 * time.Hour
+	VERIFY_LOCKOUT        = time.Minute
+	VERIFY_MAX_LOCKOUT    = 24 * time.Hour
+
+	// a phone gets a new code at most once per CODE_RESEND_INTERVAL and
+	// CODE_RESEND_LIMIT times per CODE_RESEND_WINDOW.
+	CODE_RESEND_INTERVAL = time.Minute
+	CODE_RESEND_LIMIT    = 5
+	CODE_RESEND_WINDOW   = time.Hour
+
+	// REFRESH_REUSE_GRACE is how long a replaced refresh token still passes
+	// for a concurrent refresh instead of a stolen token.
+	REFRESH_REUSE_GRACE = 30 * time.Second
+)
+
 type AuthRepositoryPSQL interface {
 	CreateUser(ctx context.Context, user *core.User) error
 	GetUserByCredentials(ctx context.Context, phone string) (*core.User, error)
 	SetTokenSession(ctx context.Context, input *core.Token) error
-	GetTokenSession(ctx context.Context, refreshToken string) (*core.Token, error)
-	DeleteTokenSession(ctx context.Context, refreshToken string) error
+	GetTokenSession(ctx context.Context, refreshTokenHash string) (*core.Token, error)
+	UpdateTokenSession(ctx context.Context, session *core.Token, rotated *core.RotatedToken, expiredBefore string) error
+	GetRotatedToken(ctx context.Context, refreshTokenHash string) (*core.RotatedToken, error)
+	GetSessionById(ctx context.Context, sessionId int) (*core.Token, error)
+	RevokeTokenFamily(ctx context.Context, sessionId int, event *core.SecurityEvent) error
+	DeleteTokenSession(ctx context.Context, refreshTokenHash string) error
+	GetSessions(ctx context.Context, userId int) ([]*core.Token, error)
+	DeleteSession(ctx context.Context, userId, sessionId int) error
+	DeleteOtherSessions(ctx context.Context, userId, sessionId int) ([]int, error)
 	CreateAvatar(ctx context.Context, avatar *core.UserAvatar) error
 }
 
 type VerifyRepositoryREDIS interface {
-	SetCode(ctx context.Context, id int, code string) error
-	Verify(ctx context.Context, code string) (string, error)
+	SetCode(ctx context.Context, phone string, userId int, code string) error
+	Verify(ctx context.Context, phone, code string) (int, error)
+	GetLockout(ctx context.Context, key string) (time.Duration, error)
+	Lock(ctx context.Context, key string, duration time.Duration) (bool, error)
+	AddAttempt(ctx context.Context, key string, window time.Duration) (int, error)
+	RemoveAttempt(ctx context.Context, key string) error
+	ResetAttempts(ctx context.Context, key string) error
+	AddCodeSend(ctx context.Context, phone string, interval, window time.Duration) (bool, int, error)
+}
+
+type AuthRepositoryREDIS interface {
+	DenyToken(ctx context.Context, tokenId string, ttl time.Duration) error
+	DenySessions(ctx context.Context, ttl time.Duration, sessionIds ...int) error
+	SetNotValidBefore(ctx context.Context, userId int, at time.Time, ttl time.Duration) error
+	GetRevocation(ctx context.Context, tokenId string, sessionId, userId int) (bool, time.Time, error)
 }
 
 type VerifyRepositoryTWILIO interface {
@@ -33,33 +74,40 @@ type VerifyRepositoryTWILIO interface {
 type Auth struct {
 	psqlRepo   AuthRepositoryPSQL
 	redisRepo  VerifyRepositoryREDIS
+	tokenRepo  AuthRepositoryREDIS
 	twilioRepo VerifyRepositoryTWILIO
 
 	manager         token.TokenManager
 	accessTokenTTL  time.Duration
 	refreshTokenTTL time.Duration
 
+	phoneSalt string
+
 	log *logrus.Logger
 }
 
-func NewAuth(psqlRepo AuthRepositoryPSQL, redisRepo VerifyRepositoryREDIS, twilioRepo VerifyRepositoryTWILIO, manager token.TokenManager, accessTokenTTL time.Duration, refreshTokenTTL time.Duration, log *logrus.Logger) *Auth {
+func NewAuth(psqlRepo AuthRepositoryPSQL, redisRepo VerifyRepositoryREDIS, tokenRepo AuthRepositoryREDIS, twilioRepo VerifyRepositoryTWILIO, manager token.TokenManager, accessTokenTTL time.Duration, refreshTokenTTL time.Duration, phoneSalt string, log *logrus.Logger) *Auth {
 	return &Auth{
 		psqlRepo:   psqlRepo,
 		redisRepo:  redisRepo,
+		tokenRepo:  tokenRepo,
 		twilioRepo: twilioRepo,
 
 		manager:         manager,
 		accessTokenTTL:  accessTokenTTL,
 		refreshTokenTTL: refreshTokenTTL,
 
+		phoneSalt: phoneSalt,
+
 		log: log,
 	}
 }
 
 func (a *Auth) Register(ctx context.Context, user *core.AuthRegister) error {
 	err := a.psqlRepo.CreateUser(ctx, &core.User{
-		Phone:    user.Phone,
-		Username: user.Username,
+		Phone:     user.Phone,
+		PhoneHash: phone.Hash(a.phoneSalt, user.Phone),
+		Username:  user.Username,
 	})
 	if err != nil {
 		return core.ErrThisCredIsAlready
@@ -74,106 +122,413 @@ func (a *Auth) Login(ctx context.Context, auth *core.AuthLogin) error {
 		return err
 	}
 
-	code := verife.GenereteCode()
+	if err := a.checkLockout(ctx, phoneAttemptKey(user.Phone)); err != nil {
+		return err
+	}
 
-	if err := a.redisRepo.SetCode(ctx, user.ID, code); err != nil {
+	ok, sent, err := a.redisRepo.AddCodeSend(ctx, user.Phone, CODE_RESEND_INTERVAL, CODE_RESEND_WINDOW)
+	if err != nil {
 		return err
+	} else if !ok || sent > CODE_RESEND_LIMIT {
+		return core.ErrCodeSentTooOften
 	}
 
-	if err := a.twilioRepo.SendCode(ctx, code, auth.Phone); err != nil {
+	code, err := verife.GenereteCode()
+	if err != nil {
+		return err
+	}
+
+	if err := a.redisRepo.SetCode(ctx, user.Phone, user.ID, code); err != nil {
+		return err
+	}
+
+	if err := a.twilioRepo.SendCode(ctx, code, user.Phone); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (a *Auth) Verify(ctx context.Context, code string) ([]string, error) {
-	id, err := a.redisRepo.Verify(ctx, code)
+// Verify exchanges the login code sent to the phone for tokens of a new
+// session on the device the request came from. Wrong codes count against
+// both the phone and the IP of the request.
+func (a *Auth) Verify(ctx context.Context, req *core.AuthVerify, info *core.SessionInfo) ([]string, error) {
+	keys := []string{phoneAttemptKey(req.Phone), ipAttemptKey(info.IP)}
+
+	for _, key := range keys {
+		if err := a.checkLockout(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, key := range keys {
+		if err := a.addAttempt(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+
+	userIdInt, err := a.redisRepo.Verify(ctx, req.Phone, req.Code)
 	if err != nil {
 		return nil, err
 	}
 
-	tokens, err := a.genereteTokens(id)
+	if err := a.redisRepo.ResetAttempts(ctx, keys[0]); err != nil {
+		a.log.Error("Error when resetting verify attempts: ", err)
+	}
+
+	if err := a.redisRepo.RemoveAttempt(ctx, keys[1]); err != nil {
+		a.log.Error("Error when removing verify attempt: ", err)
+	}
+
+	refreshToken, err := a.manager.NewRefreshToken()
 	if err != nil {
 		return nil, err
 	}
 
-	userIdInt, err := strconv.Atoi(id)
-	if err != nil {
+	now := time.Now().Format(time.DateTime)
+
+	session := &core.Token{
+		UserID:           userIdInt,
+		RefreshTokenHash: token.HashRefreshToken(refreshToken),
+		CreatedAt:        now,
+		LastUsedAt:       now,
+		DeviceName:       info.DeviceName,
+		Platform:         info.Platform,
+		IP:               info.IP,
+		UserAgent:        info.UserAgent,
+	}
+
+	if err = a.psqlRepo.SetTokenSession(ctx, session); err != nil {
 		return nil, err
 	}
 
-	if err = a.psqlRepo.SetTokenSession(ctx, &core.Token{
-		UserID:       userIdInt,
-		RefreshToken: tokens[0],
-		CreatedAt:    time.Now().Format(time.DateTime),
-	}); err != nil {
+	accessToken, err := a.manager.NewJWT(strconv.Itoa(userIdInt), strconv.Itoa(session.ID), a.accessTokenTTL)
+	if err != nil {
 		return nil, err
 	}
 
-	return tokens, nil
+	return []string{refreshToken, accessToken}, nil
 }
 
-func (a *Auth) Refresh(ctx context.Context, refreshToken string) ([]string, error) {
-	tokenSession, err := a.psqlRepo.GetTokenSession(ctx, refreshToken)
+func (a *Auth) checkLockout(ctx context.Context, key string) error {
+	lockout, err := a.redisRepo.GetLockout(ctx, key)
+	if err != nil {
+		return err
+	} else if lockout > 0 {
+		return core.ErrTooManyAttempts
+	}
+
+	return nil
+}
+
+// addAttempt counts the attempt before the code is compared, so parallel
+// requests cannot get past the limit. From VERIFY_MAX_ATTEMPTS on every
+// attempt has to take the lock of the key first, the lockout doubles each
+// time and attempts finding the key locked are refused.
+func (a *Auth) addAttempt(ctx context.Context, key string) error {
+	attempts, err := a.redisRepo.AddAttempt(ctx, key, VERIFY_FAILURE_WINDOW)
+	if err != nil {
+		return err
+	}
+
+	if attempts < VERIFY_MAX_ATTEMPTS {
+		return nil
+	}
+
+	lockout := VERIFY_MAX_LOCKOUT
+	if shift := attempts - VERIFY_MAX_ATTEMPTS; shift < 32 {
+		lockout = min(VERIFY_LOCKOUT<<shift, VERIFY_MAX_LOCKOUT)
+	}
+
+	ok, err := a.redisRepo.Lock(ctx, key, lockout)
+	if err != nil {
+		return err
+	} else if !ok {
+		return core.ErrTooManyAttempts
+	}
+
+	return nil
+}
+
+func phoneAttemptKey(phone string) string {
+	return "phone:" + phone
+}
+
+func ipAttemptKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Refresh replaces the refresh token of the session, the session expires
+// refreshTokenTTL after it was last used. A token replaced less than
+// REFRESH_REUSE_GRACE ago only gets a new access token, so concurrent
+// refreshes of one client do not fail. A token replaced before that means it
+// leaked, the whole session is revoked and returned with ErrRefreshTokenReused.
+func (a *Auth) Refresh(ctx context.Context, refreshToken string, info *core.SessionInfo) ([]string, *core.Token, error) {
+	refreshTokenHash := token.HashRefreshToken(refreshToken)
+
+	session, err := a.psqlRepo.GetTokenSession(ctx, refreshTokenHash)
 	if errors.Is(err, core.ErrRefreshTokenNotFound) {
-		return nil, core.ErrRefreshTokenIsExpired
+		return a.refreshRotated(ctx, refreshTokenHash, info)
 	} else if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	createdAt, err := time.Parse(time.DateTime, tokenSession.CreatedAt)
+	lastUsedAt, err := time.ParseInLocation(time.DateTime, session.LastUsedAt, time.Local)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	expirationTime := createdAt.Add(a.refreshTokenTTL)
+	expirationTime := lastUsedAt.Add(a.refreshTokenTTL)
 
 	if time.Now().After(expirationTime) {
-		return nil, core.ErrRefreshTokenIsExpired
+		return nil, nil, core.ErrRefreshTokenIsExpired
 	}
 
-	if err := a.psqlRepo.DeleteTokenSession(ctx, refreshToken); err != nil {
-		return nil, err
+	newRefreshToken, err := a.manager.NewRefreshToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+
+	session.RefreshTokenHash = token.HashRefreshToken(newRefreshToken)
+	session.LastUsedAt = now.Format(time.DateTime)
+	session.IP = info.IP
+	session.UserAgent = info.UserAgent
+
+	rotated := &core.RotatedToken{
+		SessionID:        session.ID,
+		UserID:           session.UserID,
+		RefreshTokenHash: refreshTokenHash,
+		RotatedAt:        now.Format(time.DateTime),
 	}
 
-	tokens, err := a.genereteTokens(strconv.Itoa(tokenSession.UserID))
+	err = a.psqlRepo.UpdateTokenSession(ctx, session, rotated, now.Add(-a.refreshTokenTTL).Format(time.DateTime))
+	if errors.Is(err, core.ErrRefreshTokenNotFound) {
+		// a concurrent refresh rotated the token first
+		return a.refreshRotated(ctx, refreshTokenHash, info)
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	accessToken, err := a.manager.NewJWT(strconv.Itoa(session.UserID), strconv.Itoa(session.ID), a.accessTokenTTL)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if err = a.psqlRepo.SetTokenSession(ctx, &core.Token{
-		UserID:       tokenSession.UserID,
-		RefreshToken: tokens[0],
-		CreatedAt:    time.Now().Format(time.DateTime),
+	return []string{newRefreshToken, accessToken}, nil, nil
+}
+
+// refreshRotated handles a refresh token that is no longer the current one
+// of its session, the returned refresh token is empty within the grace window.
+func (a *Auth) refreshRotated(ctx context.Context, refreshTokenHash string, info *core.SessionInfo) ([]string, *core.Token, error) {
+	rotated, err := a.psqlRepo.GetRotatedToken(ctx, refreshTokenHash)
+	if errors.Is(err, core.ErrRefreshTokenNotFound) {
+		return nil, nil, core.ErrRefreshTokenIsExpired
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	session, err := a.psqlRepo.GetSessionById(ctx, rotated.SessionID)
+	if errors.Is(err, core.ErrSessionNotFound) {
+		return nil, nil, core.ErrRefreshTokenIsExpired
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	rotatedAt, err := time.ParseInLocation(time.DateTime, rotated.RotatedAt, time.Local)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if time.Since(rotatedAt) <= REFRESH_REUSE_GRACE {
+		accessToken, err := a.manager.NewJWT(strconv.Itoa(session.UserID), strconv.Itoa(session.ID), a.accessTokenTTL)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return []string{"", accessToken}, nil, nil
+	}
+
+	if err := a.psqlRepo.RevokeTokenFamily(ctx, session.ID, &core.SecurityEvent{
+		UserID:    session.UserID,
+		SessionID: session.ID,
+		Type:      core.SecurityEventTokenReuse,
+		IP:        info.IP,
+		UserAgent: info.UserAgent,
+		CreatedAt: time.Now().Format(time.DateTime),
 	}); err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	if err := a.RevokeSessionTokens(ctx, session.ID); err != nil {
+		return nil, nil, err
+	}
+
+	a.log.Warnf("Refresh token reuse detected, revoked session %d of user %d", session.ID, session.UserID)
+
+	return nil, session, core.ErrRefreshTokenReused
+}
+
+// ParseToken returns the claims of the access token.
+func (a *Auth) ParseToken(accessToken string) (*token.Claims, error) {
+	return a.manager.Parse(accessToken)
+}
+
+// IsTokenRevoked reports whether the access token was revoked on its own,
+// with its session or with every token of the user.
+func (a *Auth) IsTokenRevoked(ctx context.Context, claims *token.Claims) (bool, error) {
+	var sessionId int
+	if claims.SessionID != "" {
+		var err error
+		if sessionId, err = strconv.Atoi(claims.SessionID); err != nil {
+			return false, err
+		}
+	}
+
+	userId, err := strconv.Atoi(claims.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	denied, notValidBefore, err := a.tokenRepo.GetRevocation(ctx, claims.TokenID, sessionId, userId)
+	if err != nil || denied {
+		return denied, err
 	}
 
-	return tokens, nil
+	// iat has a precision of seconds, a token issued in the second of the
+	// revocation is revoked too
+	return !notValidBefore.IsZero() && !claims.IssuedAt.After(notValidBefore), nil
 }
 
-func (a *Auth) genereteTokens(userId string) ([]string, error) {
-	accessToken, err := a.manager.NewJWT(userId, a.accessTokenTTL)
+// Logout ends the session of the access token and revokes the token right
+// away. Tokens issued before sessions were tracked have no session, their
+// session is found by the refresh token when there is one.
+func (a *Auth) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	claims, err := a.manager.Parse(accessToken)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	refreshToken, err := a.manager.NewRefreshToken()
+
+	userId, err := strconv.Atoi(claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	if claims.SessionID != "" {
+		sessionId, err := strconv.Atoi(claims.SessionID)
+		if err != nil {
+			return err
+		}
+
+		if err := a.psqlRepo.DeleteSession(ctx, userId, sessionId); err != nil && !errors.Is(err, core.ErrSessionNotFound) {
+			return err
+		}
+
+		if err := a.RevokeSessionTokens(ctx, sessionId); err != nil {
+			return err
+		}
+	} else if refreshToken != "" {
+		if err := a.psqlRepo.DeleteTokenSession(ctx, token.HashRefreshToken(refreshToken)); err != nil {
+			return err
+		}
+	}
+
+	if claims.TokenID == "" {
+		return nil
+	}
+
+	return a.tokenRepo.DenyToken(ctx, claims.TokenID, time.Until(claims.ExpiresAt))
+}
+
+// LogoutAll ends every session of the user and revokes all access tokens
+// issued so far. It returns the ids of the removed sessions.
+func (a *Auth) LogoutAll(ctx context.Context, userId int) ([]int, error) {
+	sessionIds, err := a.psqlRepo.DeleteOtherSessions(ctx, userId, 0)
 	if err != nil {
 		return nil, err
 	}
 
-	tokens := make([]string, 0, 2)
-	tokens = append(tokens, refreshToken)
-	tokens = append(tokens, accessToken)
+	if err := a.tokenRepo.SetNotValidBefore(ctx, userId, time.Now(), a.accessTokenTTL); err != nil {
+		return nil, err
+	}
 
-	return tokens, nil
+	return sessionIds, nil
 }
 
-func (a *Auth) ParseToken(token string) (string, error) {
-	return a.manager.Parse(token)
+// RevokeSessionTokens revokes the access tokens of the sessions, they are
+// valid for at most accessTokenTTL after the session is removed.
+func (a *Auth) RevokeSessionTokens(ctx context.Context, sessionIds ...int) error {
+	if len(sessionIds) == 0 {
+		return nil
+	}
+
+	return a.tokenRepo.DenySessions(ctx, a.accessTokenTTL, sessionIds...)
 }
 
 func (a *Auth) IsTokenExpired(token string) bool {
 	return a.manager.IsTokenExpired(token)
 }
+
+func (a *Auth) JWKS() *token.JWKS {
+	return a.manager.JWKS()
+}
+
+// GetSessions lists the sessions of the user that have not expired yet,
+// most recently used first.
+func (a *Auth) GetSessions(ctx context.Context, userId, currentSessionId int) ([]*core.SessionResp, error) {
+	sessions, err := a.psqlRepo.GetSessions(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*core.SessionResp, 0, len(sessions))
+	for _, session := range sessions {
+		lastUsedAt, err := time.ParseInLocation(time.DateTime, session.LastUsedAt, time.Local)
+		if err != nil || time.Now().After(lastUsedAt.Add(a.refreshTokenTTL)) {
+			continue
+		}
+
+		response = append(response, &core.SessionResp{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			Platform:   session.Platform,
+			IP:         session.IP,
+			UserAgent:  session.UserAgent,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			Current:    session.ID == currentSessionId,
+		})
+	}
+
+	return response, nil
+}
+
+func (a *Auth) RevokeSession(ctx context.Context, userId, sessionId int) error {
+	if err := a.psqlRepo.DeleteSession(ctx, userId, sessionId); err != nil {
+		return err
+	}
+
+	return a.RevokeSessionTokens(ctx, sessionId)
+}
+
+// RevokeOtherSessions logs the user out everywhere but the current session
+// and returns the ids of the revoked sessions.
+func (a *Auth) RevokeOtherSessions(ctx context.Context, userId, currentSessionId int) ([]int, error) {
+	// Tokens issued before sessions were tracked carry no session, revoking
+	// "the others" would log out the caller as well.
+	if currentSessionId == 0 {
+		return nil, core.ErrEmptySessionID
+	}
+
+	sessionIds, err := a.psqlRepo.DeleteOtherSessions(ctx, userId, currentSessionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.RevokeSessionTokens(ctx, sessionIds...); err != nil {
+		return nil, err
+	}
+
+	return sessionIds, nil
+}
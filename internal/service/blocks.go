@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+func (c *Contacts) BlockUser(ctx context.Context, req *core.BlockUserReq, userId int) error {
+	if req.UserID == userId {
+		return core.ErrCannotBlockSelf
+	}
+
+	if _, err := c.psqlRepo.GetUserById(ctx, req.UserID); err != nil {
+		return err
+	}
+
+	return c.psqlRepo.BlockUser(ctx, &core.Block{
+		UserID:        userId,
+		BlockedUserID: req.UserID,
+		CreatedAt:     time.Now().Format(time.DateTime),
+	})
+}
+
+func (c *Contacts) UnblockUser(ctx context.Context, userId, blockedUserId int) error {
+	return c.psqlRepo.UnblockUser(ctx, userId, blockedUserId)
+}
+
+func (c *Contacts) GetBlockedUsers(ctx context.Context, userId int) ([]*core.BlockedUserResp, error) {
+	blocks, err := c.psqlRepo.GetBlockedUsers(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*core.BlockedUserResp, 0, len(blocks))
+	for _, block := range blocks {
+		response = append(response, &core.BlockedUserResp{
+			UserID:    block.BlockedUserID,
+			Username:  block.BlockedUser.Username,
+			BlockedAt: block.CreatedAt,
+		})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+func (ws *WebSocket) CreateChannel(ctx context.Context, req *core.CreateChannelReq, adminID int) (int, error) {
+	handle := strings.ToLower(req.Handle)
+
+	if _, err := ws.psqlRepo.GetChatByHandle(ctx, handle); err == nil {
+		return 0, core.ErrHandleIsTaken
+	} else if !errors.Is(err, core.ErrChannelNotFound) {
+		return 0, err
+	}
+
+	chat := &core.Chat{
+		Name:        req.Name,
+		Handle:      &handle,
+		SignedPosts: req.SignedPosts,
+		AdminID:     adminID,
+		Type:        core.ChannelChatType,
+		CreatedAt:   time.Now().Format(time.DateTime),
+	}
+
+	if err := ws.psqlRepo.CreateChat(ctx, chat); err != nil {
+		return 0, err
+	}
+
+	if err := ws.psqlRepo.JoinChat(ctx, &core.ChatUser{
+		UserID: adminID,
+		ChatID: chat.ID,
+	}); err != nil {
+		return 0, err
+	}
+
+	return chat.ID, nil
+}
+
+// JoinChannel subscribes the user to the channel with the given handle.
+// Channels have no member cap and subscribing is silent: no service message
+// is stored, so subscribers never learn about each other.
+func (ws *WebSocket) JoinChannel(ctx context.Context, req *core.JoinChannelReq, userId int) (int, error) {
+	chat, err := ws.psqlRepo.GetChatByHandle(ctx, strings.ToLower(req.Handle))
+	if err != nil {
+		return 0, err
+	}
+
+	if chat.Type != core.ChannelChatType {
+		return 0, core.ErrConnotJoinChat
+	}
+
+	if err := ws.psqlRepo.JoinChat(ctx, &core.ChatUser{
+		UserID: userId,
+		ChatID: chat.ID,
+	}); err != nil {
+		return 0, err
+	}
+
+	return chat.ID, nil
+}
+
+func (ws *WebSocket) LeaveChannel(ctx context.Context, req *core.ChatUser) error {
+	chat, err := ws.psqlRepo.GetChatById(ctx, req.ChatID)
+	if err != nil {
+		return err
+	}
+
+	if chat.Type != core.ChannelChatType {
+		return core.ErrInvalideChatID
+	}
+
+	if chat.AdminID == req.UserID {
+		return core.ErrAdminCannnotLeave
+	}
+
+	return ws.psqlRepo.LeaveChatGroup(ctx, req)
+}
+
+func (ws *WebSocket) GetChannelSubscribers(ctx context.Context, chatId int) (*core.ChannelSubscribersResp, error) {
+	chat, err := ws.psqlRepo.GetChatById(ctx, chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	if chat.Type != core.ChannelChatType {
+		return nil, core.ErrInvalideChatID
+	}
+
+	count, err := ws.psqlRepo.CountChatSubscribers(ctx, chat.ID, chat.AdminID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.ChannelSubscribersResp{
+		ChatID:           chat.ID,
+		SubscribersCount: count,
+	}, nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+	"github.com/google/uuid"
+)
+
+func (ws *WebSocket) IsMember(ctx context.Context, userId, chatId int) (bool, error) {
+	if _, err := ws.psqlRepo.GetChatUser(ctx, userId, chatId); err != nil {
+		if errors.Is(err, core.ErrRecordNotFound) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (ws *WebSocket) GetChatInfo(ctx context.Context, chatId int) (*core.ChatInfoResp, error) {
+	chat, err := ws.psqlRepo.GetChatById(ctx, chatId)
+	if err != nil {
+		if errors.Is(err, core.ErrRecordNotFound) {
+			return nil, core.ErrInvalideChatID
+		}
+
+		return nil, err
+	}
+
+	var membersCount int64
+	if chat.Type == core.ChannelChatType {
+		membersCount, err = ws.psqlRepo.CountChatSubscribers(ctx, chat.ID, chat.AdminID)
+	} else {
+		membersCount, err = ws.psqlRepo.CountUsersOnChat(ctx, chat.ID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := &core.ChatInfoResp{
+		ChatID:          chat.ID,
+		Type:            chat.Type,
+		Name:            chat.Name,
+		Description:     chat.Description,
+		MembersCount:    membersCount,
+		AdminID:         chat.AdminID,
+		JoinApproval:    chat.JoinApproval,
+		SignedPosts:     chat.SignedPosts,
+		OnlyAdminsPost:  chat.OnlyAdminsPost,
+		SlowModeSeconds: chat.SlowModeSeconds,
+		TopicsEnabled:   chat.TopicsEnabled,
+		CreatedAt:       chat.CreatedAt,
+	}
+
+	if chat.AvatarKey != "" {
+		resp, err := ws.s3Repo.GetAvatar(ctx, chat.AvatarKey)
+		if err != nil {
+			return nil, err
+		}
+
+		response.AvatarUrl = resp.URL
+	}
+
+	return response, nil
+}
+
+func (ws *WebSocket) UpdateChatGroupDescription(ctx context.Context, req *core.UpdateGroupChatDescriptionReq) (*core.ChatInfoResp, error) {
+	if err := ws.psqlRepo.UpdateChatGroupDescription(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return ws.GetChatInfo(ctx, req.ChatID)
+}
+
+func (ws *WebSocket) UpdateChatGroupSettings(ctx context.Context, req *core.UpdateGroupChatSettingsReq) (*core.ChatInfoResp, error) {
+	enableTopics := req.TopicsEnabled != nil && *req.TopicsEnabled
+
+	// the chat type is checked before anything is written, so a refused
+	// request leaves every setting as it was
+	var chat *core.Chat
+	if enableTopics {
+		var err error
+		if chat, err = ws.psqlRepo.GetChatById(ctx, req.ChatID); err != nil {
+			return nil, err
+		} else if chat.Type != core.GroupChatType {
+			return nil, core.ErrTopicsGroupOnly
+		}
+	}
+
+	settings := make(map[string]any)
+
+	if req.JoinApproval != nil {
+		settings["join_approval"] = *req.JoinApproval
+	}
+
+	if req.SignedPosts != nil {
+		settings["signed_posts"] = *req.SignedPosts
+	}
+
+	if req.OnlyAdminsPost != nil {
+		settings["only_admins_post"] = *req.OnlyAdminsPost
+	}
+
+	if req.SlowModeSeconds != nil {
+		settings["slow_mode_seconds"] = *req.SlowModeSeconds
+	}
+
+	if req.TopicsEnabled != nil && !*req.TopicsEnabled {
+		settings["topics_enabled"] = false
+	}
+
+	if len(settings) != 0 {
+		if err := ws.psqlRepo.UpdateChatSettings(ctx, req.ChatID, settings); err != nil {
+			return nil, err
+		}
+	}
+
+	if enableTopics {
+		if err := ws.enableChatTopics(ctx, chat); err != nil {
+			return nil, err
+		}
+	}
+
+	return ws.GetChatInfo(ctx, req.ChatID)
+}
+
+func (ws *WebSocket) enableChatTopics(ctx context.Context, chat *core.Chat) error {
+	return ws.psqlRepo.EnableChatTopics(ctx, chat.ID, &core.ChatTopic{
+		ChatID:    chat.ID,
+		Name:      core.GeneralTopicName,
+		CreatedAt: time.Now().Format(time.DateTime),
+	})
+}
+
+// UploadChatAvatar replaces the avatar of a group or channel. Every upload
+// gets a fresh key so clients holding an old presigned url never see the
+// new image under the old address.
+func (ws *WebSocket) UploadChatAvatar(ctx context.Context, file multipart.File, chatId int) (*core.ChatInfoResp, error) {
+	chat, err := ws.psqlRepo.GetChatById(ctx, chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	if chat.Type == core.DefaultChatType {
+		return nil, core.ErrInvalideChatID
+	}
+
+	key := fmt.Sprintf("%s_%d_%s.jpg", ws.avatarKeySalt, chat.ID, uuid.NewString())
+
+	if err := ws.s3Repo.UploadAvatar(ctx, file, key); err != nil {
+		return nil, err
+	}
+
+	if err := ws.psqlRepo.UpdateChatAvatar(ctx, chat.ID, key); err != nil {
+		return nil, err
+	}
+
+	if chat.AvatarKey != "" {
+		if err := ws.s3Repo.DeleteAvatar(ctx, chat.AvatarKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return ws.GetChatInfo(ctx, chat.ID)
+}
+
+func (ws *WebSocket) DeleteChatAvatar(ctx context.Context, chatId int) (*core.ChatInfoResp, error) {
+	chat, err := ws.psqlRepo.GetChatById(ctx, chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	if chat.AvatarKey == "" {
+		return nil, core.ErrAvatarNotFound
+	}
+
+	if err := ws.s3Repo.DeleteAvatar(ctx, chat.AvatarKey); err != nil {
+		return nil, err
+	}
+
+	if err := ws.psqlRepo.UpdateChatAvatar(ctx, chat.ID, ""); err != nil {
+		return nil, err
+	}
+
+	return ws.GetChatInfo(ctx, chat.ID)
+}
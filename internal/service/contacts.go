@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+	"github.com/Woodfyn/chat-api-backend-go/pkg/phone"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	PHONE_HASH_BACKFILL_BATCH = 500
+)
+
+type ContactsRepositoryPSQL interface {
+	GetUsersByPhoneHashes(ctx context.Context, hashes []string) ([]*core.User, error)
+	SaveContacts(ctx context.Context, contacts []*core.Contact) error
+	GetContacts(ctx context.Context, userId int) ([]*core.Contact, error)
+	GetContact(ctx context.Context, userId, contactUserId int) (*core.Contact, error)
+	UpdateContactName(ctx context.Context, userId, contactUserId int, name string) error
+	DeleteContact(ctx context.Context, userId, contactUserId int) error
+	GetUsersWithoutPhoneHash(ctx context.Context, limit int) ([]*core.User, error)
+	SetPhoneHash(ctx context.Context, userId int, hash string) error
+	GetUserById(ctx context.Context, userId int) (*core.User, error)
+	BlockUser(ctx context.Context, block *core.Block) error
+	UnblockUser(ctx context.Context, userId, blockedUserId int) error
+	GetBlockedUsers(ctx context.Context, userId int) ([]*core.Block, error)
+}
+
+type Contacts struct {
+	psqlRepo ContactsRepositoryPSQL
+
+	phoneSalt string
+
+	log *logrus.Logger
+}
+
+func NewContacts(psqlRepo ContactsRepositoryPSQL, phoneSalt string, log *logrus.Logger) *Contacts {
+	return &Contacts{
+		psqlRepo: psqlRepo,
+
+		phoneSalt: phoneSalt,
+
+		log: log,
+	}
+}
+
+func (c *Contacts) GetSalt() *core.ContactsSaltResp {
+	return &core.ContactsSaltResp{Salt: c.phoneSalt}
+}
+
+// SyncContacts matches the uploaded address book against registered users
+// and stores the matches as contacts of the user. Phones that cannot be
+// normalized are skipped.
+func (c *Contacts) SyncContacts(ctx context.Context, req *core.SyncContactsReq, userId int) ([]*core.ContactResp, error) {
+	phones := make(map[string]string, len(req.Phones))
+	hashes := make([]string, 0, len(req.Phones)+len(req.Hashes))
+
+	for _, raw := range req.Phones {
+		number, err := phone.Normalize(raw)
+		if err != nil {
+			continue
+		}
+
+		hash := phone.Hash(c.phoneSalt, number)
+		phones[hash] = number
+		hashes = append(hashes, hash)
+	}
+
+	hashes = append(hashes, req.Hashes...)
+	if len(hashes) == 0 {
+		return []*core.ContactResp{}, nil
+	}
+
+	users, err := c.psqlRepo.GetUsersByPhoneHashes(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	contacts := make([]*core.Contact, 0, len(users))
+	for _, user := range users {
+		if user.ID == userId {
+			continue
+		}
+
+		contacts = append(contacts, &core.Contact{
+			UserID:        userId,
+			ContactUserID: user.ID,
+		})
+	}
+
+	if len(contacts) != 0 {
+		if err := c.psqlRepo.SaveContacts(ctx, contacts); err != nil {
+			return nil, err
+		}
+	}
+
+	saved, err := c.psqlRepo.GetContacts(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string, len(saved))
+	for _, contact := range saved {
+		names[contact.ContactUserID] = contact.Name
+	}
+
+	response := make([]*core.ContactResp, 0, len(contacts))
+	for _, user := range users {
+		if user.ID == userId {
+			continue
+		}
+
+		contactResp := &core.ContactResp{
+			UserID:   user.ID,
+			Username: user.Username,
+			Name:     names[user.ID],
+		}
+
+		// numbers are echoed only in the form the client sent them
+		if number, ok := phones[user.PhoneHash]; ok {
+			contactResp.Phone = number
+		} else {
+			contactResp.PhoneHash = user.PhoneHash
+		}
+
+		response = append(response, contactResp)
+	}
+
+	return response, nil
+}
+
+func (c *Contacts) GetContacts(ctx context.Context, userId int) ([]*core.ContactResp, error) {
+	contacts, err := c.psqlRepo.GetContacts(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*core.ContactResp, 0, len(contacts))
+	for _, contact := range contacts {
+		response = append(response, contactResp(contact))
+	}
+
+	return response, nil
+}
+
+// UpdateContact sets the custom name of the contact, an empty name
+// brings the username back.
+func (c *Contacts) UpdateContact(ctx context.Context, req *core.UpdateContactReq, userId int) (*core.ContactResp, error) {
+	contact, err := c.psqlRepo.GetContact(ctx, userId, req.ContactUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.psqlRepo.UpdateContactName(ctx, userId, req.ContactUserID, req.Name); err != nil {
+		return nil, err
+	}
+
+	contact.Name = req.Name
+
+	return contactResp(contact), nil
+}
+
+func (c *Contacts) DeleteContact(ctx context.Context, userId, contactUserId int) error {
+	if _, err := c.psqlRepo.GetContact(ctx, userId, contactUserId); err != nil {
+		return err
+	}
+
+	return c.psqlRepo.DeleteContact(ctx, userId, contactUserId)
+}
+
+// BackfillPhoneHashes hashes the phones of users registered before
+// contacts were introduced.
+func (c *Contacts) BackfillPhoneHashes(ctx context.Context) error {
+	for {
+		users, err := c.psqlRepo.GetUsersWithoutPhoneHash(ctx, PHONE_HASH_BACKFILL_BATCH)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			if err := c.psqlRepo.SetPhoneHash(ctx, user.ID, phone.Hash(c.phoneSalt, user.Phone)); err != nil {
+				return err
+			}
+		}
+
+		if len(users) < PHONE_HASH_BACKFILL_BATCH {
+			return nil
+		}
+	}
+}
+
+func contactResp(contact *core.Contact) *core.ContactResp {
+	return &core.ContactResp{
+		UserID:   contact.ContactUserID,
+		Username: contact.ContactUser.Username,
+		Name:     contact.Name,
+	}
+}
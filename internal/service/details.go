@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+func (p *Profile) UpdateProfileDetails(ctx context.Context, userId int, req *core.UpdateProfileDetailsReq) error {
+	details := make(map[string]any)
+
+	if req.DisplayName != nil {
+		details["display_name"] = *req.DisplayName
+	}
+
+	if req.Bio != nil {
+		details["bio"] = *req.Bio
+	}
+
+	if req.StatusText != nil {
+		details["status_text"] = *req.StatusText
+		details["status_expires_at"] = ""
+	}
+
+	if req.StatusExpiresAt != nil {
+		if req.StatusText == nil || *req.StatusText == "" {
+			return core.ErrStatusExpiryNoText
+		}
+
+		expiresAt, err := time.ParseInLocation(time.DateTime, *req.StatusExpiresAt, time.Local)
+		if err != nil {
+			return err
+		} else if !time.Now().Before(expiresAt) {
+			return core.ErrStatusExpired
+		}
+
+		details["status_expires_at"] = *req.StatusExpiresAt
+	}
+
+	if len(details) == 0 {
+		return nil
+	}
+
+	return p.psqlRepo.UpdateProfileDetails(ctx, userId, details)
+}
+
+// GetProfileUpdate returns the public profile sent to every chat peer of
+// the user when it changes, keyed by peer. Peers blocked by the user are
+// left out and the avatar is shown only to peers the privacy settings allow.
+func (p *Profile) GetProfileUpdate(ctx context.Context, userId int) (map[int]*core.UserProfileResp, error) {
+	profile, err := p.GetProfile(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	peerIds, err := p.psqlRepo.GetChatPeers(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[int]*core.UserProfileResp, len(peerIds))
+	for _, peerId := range peerIds {
+		blocked, err := p.psqlRepo.IsBlocked(ctx, userId, peerId)
+		if err != nil {
+			return nil, err
+		} else if blocked {
+			continue
+		}
+
+		update := &core.UserProfileResp{
+			ID:              profile.ID,
+			Username:        profile.Username,
+			DisplayName:     profile.DisplayName,
+			Bio:             profile.Bio,
+			StatusText:      profile.StatusText,
+			StatusExpiresAt: profile.StatusExpiresAt,
+		}
+
+		allowed, err := p.psqlRepo.CheckPrivacy(ctx, userId, peerId, core.PrivacyAvatar)
+		if err != nil {
+			return nil, err
+		} else if allowed {
+			update.AvatarUrl = profile.AvatarUrl
+			update.AvatarUrls = profile.AvatarUrls
+		}
+
+		updates[peerId] = update
+	}
+
+	return updates, nil
+}
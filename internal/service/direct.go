@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+// GetSavedChat returns the personal saved messages chat of the user,
+// creating it on the first call.
+func (ws *WebSocket) GetSavedChat(ctx context.Context, userId int) (int, error) {
+	return ws.getOrCreateDirectChat(ctx, &core.Chat{
+		Name: core.SavedChatName,
+		Type: core.SavedChatType,
+	}, userId, userId)
+}
+
+// ForwardMessage copies a message the user can read into another chat,
+// keeping the name of the original author.
+func (ws *WebSocket) ForwardMessage(ctx context.Context, req *core.ForwardMessageReq, userId int) (*core.ChatMessage, error) {
+	original, err := ws.psqlRepo.GetMessageById(ctx, req.MessageID)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember, err := ws.IsMember(ctx, userId, original.ChatID)
+	if err != nil {
+		return nil, err
+	} else if !isMember {
+		return nil, core.ErrMessageNotFound
+	}
+
+	forwardedFrom := original.Username
+	if original.ForwardedFrom != "" {
+		forwardedFrom = original.ForwardedFrom
+	}
+
+	return ws.sendMessage(ctx, &core.SendMessageReq{
+		ChatID:  req.ChatID,
+		TopicID: req.TopicID,
+		Text:    original.Text,
+	}, userId, forwardedFrom)
+}
+
+func (ws *WebSocket) getOrCreateDirectChat(ctx context.Context, chat *core.Chat, userId, peerId int) (int, error) {
+	if userId > peerId {
+		userId, peerId = peerId, userId
+	}
+
+	direct, err := ws.psqlRepo.GetDirectChat(ctx, userId, peerId)
+	if err == nil {
+		return direct.ChatID, nil
+	} else if !errors.Is(err, core.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	chat.CreatedAt = time.Now().Format(time.DateTime)
+
+	direct = &core.DirectChat{
+		UserID: userId,
+		PeerID: peerId,
+	}
+
+	if err := ws.psqlRepo.CreateDirectChat(ctx, chat, direct); err != nil {
+		if !errors.Is(err, core.ErrDirectChatExists) {
+			return 0, err
+		}
+
+		direct, err = ws.psqlRepo.GetDirectChat(ctx, userId, peerId)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return direct.ChatID, nil
+}
+
+// checkDirectBlock rejects direct messages to a peer who blocked the sender.
+func (ws *WebSocket) checkDirectBlock(ctx context.Context, chatId, userId int) error {
+	usersOnChat, err := ws.psqlRepo.GetUserOnChat(ctx, chatId)
+	if err != nil {
+		return err
+	}
+
+	for _, userOnChat := range usersOnChat {
+		if userOnChat.UserID == userId {
+			continue
+		}
+
+		blocked, err := ws.psqlRepo.IsBlocked(ctx, userOnChat.UserID, userId)
+		if err != nil {
+			return err
+		} else if blocked {
+			return core.ErrBlockedByUser
+		}
+	}
+
+	return nil
+}
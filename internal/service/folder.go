@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+func (ws *WebSocket) CreateChatFolder(ctx context.Context, req *core.ChatFolderReq, userId int) (*core.ChatFolderResp, error) {
+	folders, err := ws.psqlRepo.GetChatFolders(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(folders) >= MAX_CHAT_FOLDERS {
+		return nil, core.ErrTooManyFolders
+	}
+
+	chats, err := ws.folderChats(ctx, req.ChatIDs, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	position := 1
+	if len(folders) != 0 {
+		position = folders[len(folders)-1].Position + 1
+	}
+
+	folder := &core.ChatFolder{
+		UserID:          userId,
+		Name:            req.Name,
+		Position:        position,
+		IncludeDirect:   req.IncludeDirect,
+		IncludeGroups:   req.IncludeGroups,
+		IncludeChannels: req.IncludeChannels,
+		UnreadOnly:      req.UnreadOnly,
+		ExcludeMuted:    req.ExcludeMuted,
+		Chats:           chats,
+	}
+
+	if err := ws.psqlRepo.CreateChatFolder(ctx, folder); err != nil {
+		return nil, err
+	}
+
+	return chatFolderResp(folder), nil
+}
+
+func (ws *WebSocket) GetChatFolders(ctx context.Context, userId int) ([]*core.ChatFolderResp, error) {
+	folders, err := ws.psqlRepo.GetChatFolders(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*core.ChatFolderResp, 0, len(folders))
+	for _, folder := range folders {
+		response = append(response, chatFolderResp(folder))
+	}
+
+	return response, nil
+}
+
+func (ws *WebSocket) UpdateChatFolder(ctx context.Context, req *core.UpdateChatFolderReq, userId int) (*core.ChatFolderResp, error) {
+	folder, err := ws.psqlRepo.GetChatFolder(ctx, req.FolderID, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	chats, err := ws.folderChats(ctx, req.ChatIDs, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	folder.Name = req.Name
+	folder.IncludeDirect = req.IncludeDirect
+	folder.IncludeGroups = req.IncludeGroups
+	folder.IncludeChannels = req.IncludeChannels
+	folder.UnreadOnly = req.UnreadOnly
+	folder.ExcludeMuted = req.ExcludeMuted
+	folder.Chats = chats
+
+	if err := ws.psqlRepo.UpdateChatFolder(ctx, folder); err != nil {
+		return nil, err
+	}
+
+	return chatFolderResp(folder), nil
+}
+
+func (ws *WebSocket) DeleteChatFolder(ctx context.Context, folderId, userId int) error {
+	if _, err := ws.psqlRepo.GetChatFolder(ctx, folderId, userId); err != nil {
+		return err
+	}
+
+	return ws.psqlRepo.DeleteChatFolder(ctx, folderId, userId)
+}
+
+// ReorderChatFolders sets the order of the folders, the list has to
+// contain every folder of the user.
+func (ws *WebSocket) ReorderChatFolders(ctx context.Context, req *core.ReorderChatFoldersReq, userId int) error {
+	folders, err := ws.psqlRepo.GetChatFolders(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if len(folders) != len(req.FolderIDs) {
+		return core.ErrInvalidFolderOrder
+	}
+
+	folderIds := make(map[int]bool, len(folders))
+	for _, folder := range folders {
+		folderIds[folder.ID] = true
+	}
+
+	for _, folderId := range req.FolderIDs {
+		if !folderIds[folderId] {
+			return core.ErrInvalidFolderOrder
+		}
+
+		delete(folderIds, folderId)
+	}
+
+	return ws.psqlRepo.ReorderChatFolders(ctx, userId, req.FolderIDs)
+}
+
+// folderChats checks that the user is a member of every explicitly listed chat.
+func (ws *WebSocket) folderChats(ctx context.Context, chatIds []int, userId int) ([]core.ChatFolderChat, error) {
+	seen := make(map[int]bool, len(chatIds))
+
+	var chats []core.ChatFolderChat
+	for _, chatId := range chatIds {
+		if seen[chatId] {
+			continue
+		}
+		seen[chatId] = true
+
+		isMember, err := ws.IsMember(ctx, userId, chatId)
+		if err != nil {
+			return nil, err
+		} else if !isMember {
+			return nil, core.ErrNotChatMember
+		}
+
+		chats = append(chats, core.ChatFolderChat{ChatID: chatId})
+	}
+
+	return chats, nil
+}
+
+func chatFolderResp(folder *core.ChatFolder) *core.ChatFolderResp {
+	return &core.ChatFolderResp{
+		FolderID:        folder.ID,
+		Name:            folder.Name,
+		Position:        folder.Position,
+		ChatIDs:         folder.ChatIDs(),
+		IncludeDirect:   folder.IncludeDirect,
+		IncludeGroups:   folder.IncludeGroups,
+		IncludeChannels: folder.IncludeChannels,
+		UnreadOnly:      folder.UnreadOnly,
+		ExcludeMuted:    folder.ExcludeMuted,
+	}
+}
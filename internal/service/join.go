@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+// CreateJoinRequest stores a pending request to join a group that requires
+// admin approval. Repeated requests from the same user return the one that
+// is already pending.
+func (ws *WebSocket) CreateJoinRequest(ctx context.Context, req *core.ChatUser) (*core.JoinRequestResp, error) {
+	isMember, err := ws.IsMember(ctx, req.UserID, req.ChatID)
+	if err != nil {
+		return nil, err
+	} else if isMember {
+		return nil, core.ErrJoinIsAlready
+	}
+
+	request := &core.JoinRequest{
+		ChatID:    req.ChatID,
+		UserID:    req.UserID,
+		CreatedAt: time.Now().Format(time.DateTime),
+	}
+
+	if err := ws.psqlRepo.CreateJoinRequest(ctx, request); err != nil {
+		return nil, err
+	}
+
+	return ws.joinRequestResp(ctx, request)
+}
+
+func (ws *WebSocket) GetJoinRequests(ctx context.Context, chatId int) ([]*core.JoinRequestResp, error) {
+	requests, err := ws.psqlRepo.GetJoinRequests(ctx, chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []*core.JoinRequestResp
+	for _, request := range requests {
+		resp, err := ws.joinRequestResp(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		response = append(response, resp)
+	}
+
+	return response, nil
+}
+
+// ApproveJoinRequest adds the requester within the group size limit, the
+// request stays pending when the join fails. A requester who is already a
+// member counts as approved, no join message is returned for them.
+func (ws *WebSocket) ApproveJoinRequest(ctx context.Context, requestId, adminId int) (*core.JoinRequestResp, *core.ChatMessage, error) {
+	request, err := ws.adminJoinRequest(ctx, requestId, adminId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := ws.joinRequestResp(ctx, request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	msg, err := ws.joinMessage(ctx, request.UserID, request.ChatID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	joined, err := ws.psqlRepo.ApproveJoinRequest(ctx, request, MAX_ROOM_GROUP_SIZE, msg)
+	if err != nil {
+		return nil, nil, err
+	} else if !joined {
+		return resp, nil, nil
+	}
+
+	return resp, msg, nil
+}
+
+func (ws *WebSocket) DeclineJoinRequest(ctx context.Context, requestId, adminId int) (*core.JoinRequestResp, error) {
+	request, err := ws.adminJoinRequest(ctx, requestId, adminId)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ws.joinRequestResp(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ws.psqlRepo.DeleteJoinRequest(ctx, request.ID); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (ws *WebSocket) adminJoinRequest(ctx context.Context, requestId, adminId int) (*core.JoinRequest, error) {
+	request, err := ws.psqlRepo.GetJoinRequest(ctx, requestId)
+	if err != nil {
+		return nil, err
+	}
+
+	isAdmin, err := ws.IsAdmin(ctx, adminId, request.ChatID)
+	if err != nil {
+		return nil, err
+	} else if !isAdmin {
+		return nil, core.ErrNotAdmin
+	}
+
+	return request, nil
+}
+
+func (ws *WebSocket) joinRequestResp(ctx context.Context, request *core.JoinRequest) (*core.JoinRequestResp, error) {
+	chat, err := ws.psqlRepo.GetChatById(ctx, request.ChatID)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := ws.psqlRepo.GetUserById(ctx, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.JoinRequestResp{
+		RequestID: request.ID,
+		ChatID:    chat.ID,
+		ChatName:  chat.Name,
+		UserID:    user.ID,
+		Username:  user.Username,
+		CreatedAt: request.CreatedAt,
+	}, nil
+}
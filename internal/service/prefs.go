@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+func (ws *WebSocket) UpdateChatPrefs(ctx context.Context, req *core.UpdateChatPrefsReq, userId int) error {
+	chatUser, err := ws.psqlRepo.GetChatUser(ctx, userId, req.ChatID)
+	if err != nil {
+		if errors.Is(err, core.ErrRecordNotFound) {
+			return core.ErrNotChatMember
+		}
+
+		return err
+	}
+
+	prefs := make(map[string]any)
+
+	if req.Archived != nil {
+		prefs["archived"] = *req.Archived
+	}
+
+	if req.MutedUntil != nil {
+		prefs["muted_until"] = *req.MutedUntil
+	}
+
+	if req.Pinned != nil {
+		switch {
+		case !*req.Pinned:
+			prefs["pin_order"] = 0
+		case chatUser.PinOrder == 0:
+			pinned, err := ws.psqlRepo.GetPinnedChats(ctx, userId)
+			if err != nil {
+				return err
+			}
+
+			pinOrder := 1
+			if len(pinned) != 0 {
+				pinOrder = pinned[len(pinned)-1].PinOrder + 1
+			}
+
+			prefs["pin_order"] = pinOrder
+		}
+	}
+
+	if len(prefs) == 0 {
+		return nil
+	}
+
+	return ws.psqlRepo.UpdateChatUserPrefs(ctx, userId, req.ChatID, prefs)
+}
+
+// ReorderPinnedChats sets the order of pinned chats on the wall,
+// the list has to contain every pinned chat of the user.
+func (ws *WebSocket) ReorderPinnedChats(ctx context.Context, req *core.ReorderPinnedChatsReq, userId int) error {
+	pinned, err := ws.psqlRepo.GetPinnedChats(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	if len(pinned) != len(req.ChatIDs) {
+		return core.ErrInvalidPinnedChats
+	}
+
+	pinnedIds := make(map[int]bool, len(pinned))
+	for _, chatUser := range pinned {
+		pinnedIds[chatUser.ChatID] = true
+	}
+
+	for _, chatId := range req.ChatIDs {
+		if !pinnedIds[chatId] {
+			return core.ErrInvalidPinnedChats
+		}
+
+		delete(pinnedIds, chatId)
+	}
+
+	return ws.psqlRepo.ReorderPinnedChats(ctx, userId, req.ChatIDs)
+}
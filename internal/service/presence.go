@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+// SetOnline marks a new stream of the user, the presence is returned
+// only when the user has just come online.
+func (ws *WebSocket) SetOnline(ctx context.Context, userId int) (*core.PresenceResp, error) {
+	changed, err := ws.redisRepo.SetOnline(ctx, userId, PRESENCE_TTL)
+	if err != nil || !changed {
+		return nil, err
+	}
+
+	return &core.PresenceResp{
+		UserID: userId,
+		Online: true,
+	}, nil
+}
+
+// RefreshOnline keeps the user online, it is called while a stream of the
+// user is alive.
+func (ws *WebSocket) RefreshOnline(ctx context.Context, userId int) error {
+	return ws.redisRepo.RefreshOnline(ctx, userId, PRESENCE_TTL)
+}
+
+// SetOffline marks a closed stream of the user, the presence is returned
+// only when the last stream of the user is gone.
+func (ws *WebSocket) SetOffline(ctx context.Context, userId int) (*core.PresenceResp, error) {
+	now := time.Now()
+
+	changed, err := ws.redisRepo.SetOffline(ctx, userId, now)
+	if err != nil || !changed {
+		return nil, err
+	}
+
+	return &core.PresenceResp{
+		UserID:   userId,
+		LastSeen: now.Format(time.DateTime),
+	}, nil
+}
+
+// ExpirePresence takes offline the users whose streams stopped refreshing
+// their presence without closing, like the streams of a crashed instance.
+// It returns the presence of those users.
+func (ws *WebSocket) ExpirePresence(ctx context.Context) ([]*core.PresenceResp, error) {
+	return ws.redisRepo.ExpireOnline(ctx, time.Now(), PRESENCE_TTL)
+}
+
+// GetPresence returns the presence of the users, users hiding their
+// last seen time from the viewer are shown as offline without it.
+func (ws *WebSocket) GetPresence(ctx context.Context, req *core.PresenceReq, viewerId int) ([]*core.PresenceResp, error) {
+	presence, err := ws.redisRepo.GetPresence(ctx, req.UserIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, userPresence := range presence {
+		allowed, err := ws.psqlRepo.CheckPrivacy(ctx, userPresence.UserID, viewerId, core.PrivacyLastSeen)
+		if err != nil {
+			return nil, err
+		}
+
+		if !allowed {
+			presence[i] = &core.PresenceResp{UserID: userPresence.UserID}
+		}
+	}
+
+	return presence, nil
+}
+
+// GetPresenceWatchers returns the chat peers of the user allowed to see
+// their last seen time.
+func (ws *WebSocket) GetPresenceWatchers(ctx context.Context, userId int) ([]int, error) {
+	peerIds, err := ws.psqlRepo.GetChatPeers(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	watchers := make([]int, 0, len(peerIds))
+	for _, peerId := range peerIds {
+		allowed, err := ws.psqlRepo.CheckPrivacy(ctx, userId, peerId, core.PrivacyLastSeen)
+		if err != nil {
+			return nil, err
+		}
+
+		if allowed {
+			watchers = append(watchers, peerId)
+		}
+	}
+
+	return watchers, nil
+}
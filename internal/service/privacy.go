@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+// GetPrivacy returns every privacy setting of the user, settings the
+// user has never changed come with their default level.
+func (p *Profile) GetPrivacy(ctx context.Context, userId int) ([]*core.PrivacySettingResp, error) {
+	settings, err := p.psqlRepo.GetPrivacySettings(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	exceptions, err := p.psqlRepo.GetPrivacyExceptions(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	bySetting := make(map[string]*core.PrivacySettingResp, len(core.PrivacySettings))
+	for setting, level := range core.PrivacySettings {
+		bySetting[setting] = &core.PrivacySettingResp{
+			Setting:     setting,
+			Level:       level,
+			AlwaysAllow: []int{},
+			NeverAllow:  []int{},
+		}
+	}
+
+	for _, setting := range settings {
+		if resp, ok := bySetting[setting.Setting]; ok {
+			resp.Level = setting.Level
+		}
+	}
+
+	for _, exception := range exceptions {
+		resp, ok := bySetting[exception.Setting]
+		if !ok {
+			continue
+		}
+
+		if exception.Allow {
+			resp.AlwaysAllow = append(resp.AlwaysAllow, exception.TargetUserID)
+		} else {
+			resp.NeverAllow = append(resp.NeverAllow, exception.TargetUserID)
+		}
+	}
+
+	response := make([]*core.PrivacySettingResp, 0, len(bySetting))
+	for _, resp := range bySetting {
+		response = append(response, resp)
+	}
+
+	sort.Slice(response, func(i, j int) bool {
+		return response[i].Setting < response[j].Setting
+	})
+
+	return response, nil
+}
+
+func (p *Profile) UpdatePrivacy(ctx context.Context, req *core.UpdatePrivacyReq, userId int) (*core.PrivacySettingResp, error) {
+	response := &core.PrivacySettingResp{
+		Setting:     req.Setting,
+		Level:       req.Level,
+		AlwaysAllow: []int{},
+		NeverAllow:  []int{},
+	}
+
+	seen := make(map[int]bool, len(req.AlwaysAllow)+len(req.NeverAllow))
+
+	var exceptions []*core.PrivacyException
+	for _, targetId := range req.AlwaysAllow {
+		if targetId == userId || seen[targetId] {
+			continue
+		}
+		seen[targetId] = true
+
+		exceptions = append(exceptions, &core.PrivacyException{UserID: userId, Setting: req.Setting, TargetUserID: targetId, Allow: true})
+		response.AlwaysAllow = append(response.AlwaysAllow, targetId)
+	}
+
+	for _, targetId := range req.NeverAllow {
+		if targetId == userId || seen[targetId] {
+			continue
+		}
+		seen[targetId] = true
+
+		exceptions = append(exceptions, &core.PrivacyException{UserID: userId, Setting: req.Setting, TargetUserID: targetId})
+		response.NeverAllow = append(response.NeverAllow, targetId)
+	}
+
+	if err := p.psqlRepo.UpdatePrivacySetting(ctx, &core.PrivacySetting{
+		UserID:  userId,
+		Setting: req.Setting,
+		Level:   req.Level,
+	}, exceptions); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
@@ -1,44 +1,69 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"time"
 
 	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+	"github.com/Woodfyn/chat-api-backend-go/pkg/image"
 	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/sirupsen/logrus"
 )
 
+const MAX_AVATAR_FILE_SIZE = 10 << 20
+
+var AVATAR_SIZES = []int{64, 256, 640}
+
 type ProfileRepositoryPSQL interface {
 	GetProfile(ctx context.Context, userId int) (*core.User, error)
 	UpdateProfile(ctx context.Context, user *core.User) error
+	UpdateProfileDetails(ctx context.Context, userId int, details map[string]any) error
+	GetChatPeers(ctx context.Context, userId int) ([]int, error)
 	SaveAvatar(ctx context.Context, avatar *core.UserAvatar) error
 	GetAvatars(ctx context.Context, userId int) ([]*core.UserAvatar, error)
 	GetAvatar(ctx context.Context, avatarId int) (*core.UserAvatar, error)
 	DeleteAvatar(ctx context.Context, id int) error
+	SetCurrentAvatar(ctx context.Context, userId, avatarId int) error
+	ReorderAvatars(ctx context.Context, userId int, avatarIds []int) error
+	IsBlocked(ctx context.Context, userId, blockedUserId int) (bool, error)
+	CheckPrivacy(ctx context.Context, ownerId, viewerId int, setting string) (bool, error)
+	GetPrivacySettings(ctx context.Context, userId int) ([]*core.PrivacySetting, error)
+	GetPrivacyExceptions(ctx context.Context, userId int) ([]*core.PrivacyException, error)
+	UpdatePrivacySetting(ctx context.Context, setting *core.PrivacySetting, exceptions []*core.PrivacyException) error
+	GetUserByUsername(ctx context.Context, username string) (*core.User, error)
+	SearchUsers(ctx context.Context, query string, viewerId, limit int) ([]*core.User, error)
+}
+
+type ProfileRepositoryREDIS interface {
+	AllowSearch(ctx context.Context, userId, limit int, window time.Duration) (bool, error)
 }
 
 type ProfileRepositoryS3 interface {
 	GetAvatars(ctx context.Context, key string) (*v4.PresignedHTTPRequest, error)
-	UploadAvatar(ctx context.Context, file multipart.File, key string) error
+	UploadAvatar(ctx context.Context, file io.Reader, key string) error
 	DeleteAvatar(ctx context.Context, key string) error
 }
 
 type Profile struct {
-	psqlRepo ProfileRepositoryPSQL
-	s3Repo   ProfileRepositoryS3
+	psqlRepo  ProfileRepositoryPSQL
+	redisRepo ProfileRepositoryREDIS
+	s3Repo    ProfileRepositoryS3
 
 	avatarKeySalt string
 
 	log *logrus.Logger
 }
 
-func NewProfile(psqlRepo ProfileRepositoryPSQL, s3Repo ProfileRepositoryS3, avatarKeySalt string, log *logrus.Logger) *Profile {
+func NewProfile(psqlRepo ProfileRepositoryPSQL, redisRepo ProfileRepositoryREDIS, s3Repo ProfileRepositoryS3, avatarKeySalt string, log *logrus.Logger) *Profile {
 	return &Profile{
-		psqlRepo: psqlRepo,
-		s3Repo:   s3Repo,
+		psqlRepo:  psqlRepo,
+		redisRepo: redisRepo,
+		s3Repo:    s3Repo,
 
 		avatarKeySalt: avatarKeySalt,
 
@@ -52,32 +77,75 @@ func (p *Profile) GetProfile(ctx context.Context, userId int) (*core.GetProfileR
 		return nil, err
 	}
 
+	response := &core.GetProfileResp{
+		ID:          user.ID,
+		Username:    user.Username,
+		Phone:       user.Phone,
+		DisplayName: user.DisplayName,
+		Bio:         user.Bio,
+
+		DeletionScheduledAt: user.DeletionScheduledAt,
+	}
+	response.StatusText, response.StatusExpiresAt = user.ActiveStatus(time.Now())
+
 	userAvatars, err := p.psqlRepo.GetAvatars(ctx, user.ID)
 	if err != nil {
 		if errors.Is(err, core.ErrAvatarNotFound) {
-			return &core.GetProfileResp{
-				ID:       user.ID,
-				Username: user.Username,
-				Phone:    user.Phone,
-			}, nil
+			return response, nil
 		}
 
 		return nil, err
 	}
 
-	key := fmt.Sprintf("%s_%d_%d.jpg", p.avatarKeySalt, user.ID, userAvatars[len(userAvatars)-1].ID)
+	response.AvatarUrl, response.AvatarUrls, err = p.avatarUrls(ctx, currentAvatar(user, userAvatars))
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// GetUserProfile returns the profile of another user, users blocked by
+// its owner get ErrUserNotFound. Phone and avatar are shown only when the
+// privacy settings of the owner allow it.
+func (p *Profile) GetUserProfile(ctx context.Context, userId, viewerId int) (*core.UserProfileResp, error) {
+	blocked, err := p.psqlRepo.IsBlocked(ctx, userId, viewerId)
+	if err != nil {
+		return nil, err
+	} else if blocked {
+		return nil, core.ErrUserNotFound
+	}
+
+	profile, err := p.GetProfile(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &core.UserProfileResp{
+		ID:              profile.ID,
+		Username:        profile.Username,
+		DisplayName:     profile.DisplayName,
+		Bio:             profile.Bio,
+		StatusText:      profile.StatusText,
+		StatusExpiresAt: profile.StatusExpiresAt,
+	}
+
+	allowed, err := p.psqlRepo.CheckPrivacy(ctx, userId, viewerId, core.PrivacyPhone)
+	if err != nil {
+		return nil, err
+	} else if allowed {
+		response.Phone = profile.Phone
+	}
 
-	resp, err := p.s3Repo.GetAvatars(ctx, key)
+	allowed, err = p.psqlRepo.CheckPrivacy(ctx, userId, viewerId, core.PrivacyAvatar)
 	if err != nil {
 		return nil, err
+	} else if allowed {
+		response.AvatarUrl = profile.AvatarUrl
+		response.AvatarUrls = profile.AvatarUrls
 	}
 
-	return &core.GetProfileResp{
-		ID:        user.ID,
-		Username:  user.Username,
-		Phone:     user.Phone,
-		AvatarUrl: resp.URL,
-	}, nil
+	return response, nil
 }
 
 func (p *Profile) UpdateProfile(ctx context.Context, user *core.User) error {
@@ -88,19 +156,47 @@ func (p *Profile) UpdateProfile(ctx context.Context, user *core.User) error {
 	return nil
 }
 
+// UploadAvatar stores the upload as square JPEG images of AVATAR_SIZES,
+// anything that is not an image is rejected before it reaches S3.
 func (p *Profile) UploadAvatar(ctx context.Context, file multipart.File, userId int) error {
+	data, err := io.ReadAll(io.LimitReader(file, MAX_AVATAR_FILE_SIZE+1))
+	if err != nil {
+		return err
+	} else if len(data) > MAX_AVATAR_FILE_SIZE {
+		return core.ErrImageTooLarge
+	}
+
+	images, err := image.ProcessAvatar(data, AVATAR_SIZES)
+	if err != nil {
+		switch {
+		case errors.Is(err, image.ErrInvalidImage):
+			return core.ErrInvalidImage
+		case errors.Is(err, image.ErrImageTooLarge):
+			return core.ErrImageTooLarge
+		}
+
+		return err
+	}
+
 	avatar := &core.UserAvatar{
 		UserID: userId,
 	}
+	for _, size := range AVATAR_SIZES {
+		avatar.Sizes = append(avatar.Sizes, int64(size))
+	}
 
 	if err := p.psqlRepo.SaveAvatar(ctx, avatar); err != nil {
 		return err
 	}
 
-	key := fmt.Sprintf("%s_%d_%d.jpg", p.avatarKeySalt, userId, avatar.ID)
+	for _, size := range AVATAR_SIZES {
+		if err := p.s3Repo.UploadAvatar(ctx, bytes.NewReader(images[size]), avatarKey(p.avatarKeySalt, userId, avatar.ID, int64(size))); err != nil {
+			if err := p.psqlRepo.DeleteAvatar(ctx, avatar.ID); err != nil {
+				p.log.Error("Error when deleting avatar: ", err)
+			}
 
-	if err := p.s3Repo.UploadAvatar(ctx, file, key); err != nil {
-		return err
+			return err
+		}
 	}
 
 	return nil
@@ -116,18 +212,26 @@ func (p *Profile) GetAvatars(ctx context.Context, userId int) ([]*core.GetAllUse
 		return nil, err
 	}
 
+	user, err := p.psqlRepo.GetProfile(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	current := currentAvatar(user, avatars)
+
 	var response []*core.GetAllUserAvatarsResp
 	for _, avatar := range avatars {
-		key := fmt.Sprintf("%s_%d_%d.jpg", p.avatarKeySalt, userId, avatar.ID)
-
-		output, err := p.s3Repo.GetAvatars(ctx, key)
+		url, urls, err := p.avatarUrls(ctx, avatar)
 		if err != nil {
 			return nil, err
 		}
 
 		response = append(response, &core.GetAllUserAvatarsResp{
-			ID:        avatar.ID,
-			AvatarUrl: output.URL,
+			ID:         avatar.ID,
+			AvatarUrl:  url,
+			AvatarUrls: urls,
+			Position:   avatar.Position,
+			Current:    avatar == current,
 		})
 	}
 
@@ -135,14 +239,15 @@ func (p *Profile) GetAvatars(ctx context.Context, userId int) ([]*core.GetAllUse
 }
 
 func (p *Profile) DeleteAvatar(ctx context.Context, userId int, avatarId int) error {
-	if _, err := p.psqlRepo.GetAvatar(ctx, avatarId); err != nil {
+	avatar, err := p.psqlRepo.GetAvatar(ctx, avatarId)
+	if err != nil || avatar.UserID != userId {
 		return core.ErrAvatarNotFound
 	}
 
-	key := fmt.Sprintf("%s_%d_%d.jpg", p.avatarKeySalt, userId, avatarId)
-
-	if err := p.s3Repo.DeleteAvatar(ctx, key); err != nil {
-		return err
+	for _, key := range avatarKeys(p.avatarKeySalt, avatar) {
+		if err := p.s3Repo.DeleteAvatar(ctx, key); err != nil {
+			return err
+		}
 	}
 
 	if err := p.psqlRepo.DeleteAvatar(ctx, avatarId); err != nil {
@@ -151,3 +256,102 @@ func (p *Profile) DeleteAvatar(ctx context.Context, userId int, avatarId int) er
 
 	return nil
 }
+
+func (p *Profile) SetCurrentAvatar(ctx context.Context, req *core.SetCurrentAvatarReq, userId int) error {
+	avatar, err := p.psqlRepo.GetAvatar(ctx, req.AvatarID)
+	if err != nil || avatar.UserID != userId {
+		return core.ErrAvatarNotFound
+	}
+
+	return p.psqlRepo.SetCurrentAvatar(ctx, userId, avatar.ID)
+}
+
+// ReorderAvatars sets the order of the gallery, the list has to contain
+// every avatar of the user.
+func (p *Profile) ReorderAvatars(ctx context.Context, req *core.ReorderAvatarsReq, userId int) error {
+	avatars, err := p.psqlRepo.GetAvatars(ctx, userId)
+	if err != nil && !errors.Is(err, core.ErrAvatarNotFound) {
+		return err
+	}
+
+	if len(avatars) != len(req.AvatarIDs) {
+		return core.ErrInvalidAvatarOrder
+	}
+
+	avatarIds := make(map[int]bool, len(avatars))
+	for _, avatar := range avatars {
+		avatarIds[avatar.ID] = true
+	}
+
+	for _, avatarId := range req.AvatarIDs {
+		if !avatarIds[avatarId] {
+			return core.ErrInvalidAvatarOrder
+		}
+
+		delete(avatarIds, avatarId)
+	}
+
+	return p.psqlRepo.ReorderAvatars(ctx, userId, req.AvatarIDs)
+}
+
+// currentAvatar returns the avatar the user picked or, without a choice,
+// the last one in the gallery. Avatars must not be empty.
+func currentAvatar(user *core.User, avatars []*core.UserAvatar) *core.UserAvatar {
+	for _, avatar := range avatars {
+		if avatar.ID == user.CurrentAvatarID {
+			return avatar
+		}
+	}
+
+	return avatars[len(avatars)-1]
+}
+
+// avatarUrls presigns every size of the avatar, the main url points to the
+// largest one.
+func (p *Profile) avatarUrls(ctx context.Context, avatar *core.UserAvatar) (string, map[int64]string, error) {
+	if len(avatar.Sizes) == 0 {
+		resp, err := p.s3Repo.GetAvatars(ctx, avatarKey(p.avatarKeySalt, avatar.UserID, avatar.ID, 0))
+		if err != nil {
+			return "", nil, err
+		}
+
+		return resp.URL, nil, nil
+	}
+
+	var url string
+	urls := make(map[int64]string, len(avatar.Sizes))
+	for _, size := range avatar.Sizes {
+		resp, err := p.s3Repo.GetAvatars(ctx, avatarKey(p.avatarKeySalt, avatar.UserID, avatar.ID, size))
+		if err != nil {
+			return "", nil, err
+		}
+
+		urls[size] = resp.URL
+		url = resp.URL
+	}
+
+	return url, urls, nil
+}
+
+// avatarKey returns the S3 key of one size of the avatar, size zero is the
+// original upload of avatars stored before processing.
+func avatarKey(salt string, userId, avatarId int, size int64) string {
+	if size == 0 {
+		return fmt.Sprintf("%s_%d_%d.jpg", salt, userId, avatarId)
+	}
+
+	return fmt.Sprintf("%s_%d_%d_%d.jpg", salt, userId, avatarId, size)
+}
+
+func avatarKeys(salt string, avatar *core.UserAvatar) []string {
+	if len(avatar.Sizes) == 0 {
+		return []string{avatarKey(salt, avatar.UserID, avatar.ID, 0)}
+	}
+
+	keys := make([]string, 0, len(avatar.Sizes))
+	for _, size := range avatar.Sizes {
+		keys = append(keys, avatarKey(salt, avatar.UserID, avatar.ID, size))
+	}
+
+	return keys
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+const (
+	USER_SEARCH_LIMIT   = 30
+	USER_SEARCH_WINDOW  = time.Minute
+	USER_SEARCH_RESULTS = 20
+)
+
+func (p *Profile) GetUserProfileByUsername(ctx context.Context, username string, viewerId int) (*core.UserProfileResp, error) {
+	user, err := p.psqlRepo.GetUserByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.GetUserProfile(ctx, user.ID, viewerId)
+}
+
+// SearchUsers finds users by username prefix, each user may run
+// USER_SEARCH_LIMIT searches per USER_SEARCH_WINDOW.
+func (p *Profile) SearchUsers(ctx context.Context, req *core.SearchUsersReq, viewerId int) ([]*core.UserSearchResp, error) {
+	allowed, err := p.redisRepo.AllowSearch(ctx, viewerId, USER_SEARCH_LIMIT, USER_SEARCH_WINDOW)
+	if err != nil {
+		return nil, err
+	} else if !allowed {
+		return nil, core.ErrTooManyRequests
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = USER_SEARCH_RESULTS
+	}
+
+	users, err := p.psqlRepo.SearchUsers(ctx, req.Query, viewerId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*core.UserSearchResp, 0, len(users))
+	for _, user := range users {
+		response = append(response, &core.UserSearchResp{
+			ID:          user.ID,
+			Username:    user.Username,
+			DisplayName: user.DisplayName,
+		})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+func (ws *WebSocket) CreateChatTopic(ctx context.Context, req *core.CreateChatTopicReq) (*core.ChatTopicResp, error) {
+	chat, err := ws.psqlRepo.GetChatById(ctx, req.ChatID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !chat.TopicsEnabled {
+		return nil, core.ErrTopicsDisabled
+	}
+
+	topic := &core.ChatTopic{
+		ChatID:    chat.ID,
+		Name:      req.Name,
+		CreatedAt: time.Now().Format(time.DateTime),
+	}
+
+	if err := ws.psqlRepo.CreateChatTopic(ctx, topic); err != nil {
+		return nil, err
+	}
+
+	return &core.ChatTopicResp{
+		TopicID:   topic.ID,
+		ChatID:    topic.ChatID,
+		Name:      topic.Name,
+		CreatedAt: topic.CreatedAt,
+	}, nil
+}
+
+// GetChatTopics lists the topics of the chat with their last message
+// and the unread count of the user.
+func (ws *WebSocket) GetChatTopics(ctx context.Context, chatId, userId int) ([]*core.ChatTopicResp, error) {
+	chat, err := ws.psqlRepo.GetChatById(ctx, chatId)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember, err := ws.IsMember(ctx, userId, chat.ID)
+	if err != nil {
+		return nil, err
+	} else if !isMember {
+		return nil, core.ErrNotChatMember
+	}
+
+	names, err := ws.psqlRepo.GetContactNames(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return ws.chatTopics(ctx, chat, userId, names)
+}
+
+func (ws *WebSocket) SearchMessages(ctx context.Context, chatId, topicId, userId int, text string) ([]*core.ChatMessage, error) {
+	if text == "" {
+		return nil, core.ErrEmptySearchText
+	}
+
+	isMember, err := ws.IsMember(ctx, userId, chatId)
+	if err != nil {
+		return nil, err
+	} else if !isMember {
+		return nil, core.ErrNotChatMember
+	}
+
+	if topicId != 0 {
+		if err := ws.checkTopic(ctx, chatId, topicId); err != nil {
+			return nil, err
+		}
+	}
+
+	messages, err := ws.psqlRepo.SearchMessages(ctx, chatId, topicId, text, MAX_SEARCH_RESULTS)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ws.applyContactNames(ctx, chatId, userId, messages); err != nil {
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func (ws *WebSocket) chatTopics(ctx context.Context, chat *core.Chat, userId int, names map[int]string) ([]*core.ChatTopicResp, error) {
+	topics, err := ws.psqlRepo.GetChatTopics(ctx, chat.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*core.ChatTopicResp, 0, len(topics))
+	for _, topic := range topics {
+		topicResp := &core.ChatTopicResp{
+			TopicID:   topic.ID,
+			ChatID:    topic.ChatID,
+			Name:      topic.Name,
+			CreatedAt: topic.CreatedAt,
+		}
+
+		lastMessage, err := ws.psqlRepo.GetLastTopicMessage(ctx, topic.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if lastMessage != nil {
+			topicResp.LastMessage = wallLastMessage(chat, lastMessage, userId, names)
+		}
+
+		topicResp.UnreadCount, err = ws.psqlRepo.CountUnreadTopicMessages(ctx, userId, topic.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		response = append(response, topicResp)
+	}
+
+	return response, nil
+}
+
+// messageTopic checks the topic of a new message against the mode of the chat.
+func (ws *WebSocket) messageTopic(ctx context.Context, chat *core.Chat, topicId int) error {
+	if !chat.TopicsEnabled {
+		if topicId != 0 {
+			return core.ErrTopicsDisabled
+		}
+
+		return nil
+	}
+
+	if topicId == 0 {
+		return core.ErrTopicRequired
+	}
+
+	return ws.checkTopic(ctx, chat.ID, topicId)
+}
+
+func (ws *WebSocket) checkTopic(ctx context.Context, chatId, topicId int) error {
+	topic, err := ws.psqlRepo.GetChatTopic(ctx, topicId)
+	if err != nil {
+		return err
+	}
+
+	if topic.ChatID != chatId {
+		return core.ErrTopicNotFound
+	}
+
+	return nil
+}
+
+// markRead moves the read marker of the topic for topic messages
+// and the one of the chat otherwise.
+func (ws *WebSocket) markRead(ctx context.Context, userId, chatId, topicId, messageId int) error {
+	if topicId != 0 {
+		return ws.psqlRepo.MarkTopicRead(ctx, userId, topicId, messageId)
+	}
+
+	return ws.psqlRepo.MarkMessagesRead(ctx, userId, chatId, messageId)
+}
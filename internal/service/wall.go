@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+// GetWall returns the chats of the user, pinned chats go first in their
+// pin order. Archived chats are listed only when archived is set, a non
+// zero folderId keeps only the chats matching that folder.
+func (ws *WebSocket) GetWall(ctx context.Context, userId int, archived bool, folderId int) ([]*core.WallChatResp, error) {
+	var folder *core.ChatFolder
+	if folderId != 0 {
+		var err error
+		folder, err = ws.psqlRepo.GetChatFolder(ctx, folderId, userId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	chatUsers, err := ws.psqlRepo.GetWall(ctx, userId, archived)
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := ws.psqlRepo.GetContactNames(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	var response []*core.WallChatResp
+	for _, chatUser := range chatUsers {
+		chat, err := ws.psqlRepo.GetChatById(ctx, chatUser.ChatID)
+		if err != nil {
+			return nil, err
+		}
+
+		name := chat.Name
+		if chat.Type == core.DefaultChatType {
+			name, err = ws.directChatName(ctx, chat.ID, userId, names)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		wallChat := &core.WallChatResp{
+			ChatID:   chat.ID,
+			Name:     name,
+			Archived: chatUser.Archived,
+			Pinned:   chatUser.PinOrder != 0,
+			Muted:    chatUser.IsMuted(now),
+		}
+
+		if wallChat.Muted {
+			wallChat.MutedUntil = chatUser.MutedUntil
+		}
+
+		if chat.TopicsEnabled {
+			wallChat.Topics, err = ws.chatTopics(ctx, chat, userId, names)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, topic := range wallChat.Topics {
+				wallChat.UnreadCount += topic.UnreadCount
+			}
+		} else {
+			wallChat.UnreadCount, err = ws.psqlRepo.CountUnreadMessages(ctx, chatUser)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if folder != nil && !folder.Matches(chat.Type, wallChat) {
+			continue
+		}
+
+		lastMessage, err := ws.psqlRepo.GetLastMessage(ctx, chat.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		if lastMessage != nil {
+			wallChat.LastMessage = wallLastMessage(chat, lastMessage, userId, names)
+		}
+
+		response = append(response, wallChat)
+	}
+
+	return response, nil
+}
+
+// directChatName names a direct chat after the other member,
+// using the contact name when the user has set one.
+func (ws *WebSocket) directChatName(ctx context.Context, chatId, userId int, names map[int]string) (string, error) {
+	usersOnChat, err := ws.GetUserOnChat(ctx, chatId)
+	if err != nil {
+		return "", err
+	}
+
+	for _, userOnChat := range usersOnChat {
+		if userOnChat.UserID != userId {
+			if name, ok := names[userOnChat.UserID]; ok {
+				return name, nil
+			}
+
+			user, err := ws.psqlRepo.GetUserById(ctx, userOnChat.UserID)
+			if err != nil {
+				return "", err
+			}
+
+			return user.Username, nil
+		}
+	}
+
+	return "", nil
+}
+
+func wallLastMessage(chat *core.Chat, msg *core.ChatMessage, userId int, names map[int]string) string {
+	switch chat.Type {
+	case core.DefaultChatType:
+		if msg.UserID != userId {
+			return msg.Text
+		}
+	case core.ChannelChatType, core.SavedChatType:
+		return msg.Text
+	default:
+		if msg.UserID == userId {
+			return msg.Text
+		}
+	}
+
+	username := msg.Username
+	if name, ok := names[msg.UserID]; ok {
+		username = name
+	}
+
+	return fmt.Sprintf("%s: %s", username, msg.Text)
+}
+
+// applyContactNames shows the authors of the messages under the contact
+// names the user has set. Channel posts keep the name of the channel.
+func (ws *WebSocket) applyContactNames(ctx context.Context, chatId, userId int, messages []*core.ChatMessage) error {
+	chat, err := ws.psqlRepo.GetChatById(ctx, chatId)
+	if err != nil {
+		return err
+	}
+
+	if chat.Type == core.ChannelChatType {
+		return nil
+	}
+
+	names, err := ws.psqlRepo.GetContactNames(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	for _, message := range messages {
+		if name, ok := names[message.UserID]; ok {
+			message.Username = name
+		}
+	}
+
+	return nil
+}
@@ -4,41 +4,114 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"time"
 
 	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/sirupsen/logrus"
 )
 
 const (
 	MAX_ROOM_GROUP_SIZE = 10
+	MAX_CHAT_FOLDERS    = 10
+	MAX_SEARCH_RESULTS  = 50
+
+	// PRESENCE_TTL is how long a user stays online after the last refresh
+	// of a live stream, ExpirePresence then takes the user offline.
+	PRESENCE_TTL = 2 * time.Minute
 )
 
 type WSRepositoryPSQL interface {
 	GetUserById(ctx context.Context, userId int) (*core.User, error)
 	GetUserByPhone(ctx context.Context, phone string) (*core.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*core.User, error)
 	GetUserOnChat(ctx context.Context, chatId int) ([]*core.ChatUser, error)
 	SaveMessage(ctx context.Context, msg *core.ChatMessage) error
-	GetWall(ctx context.Context, userId int) ([]*core.Chat, error)
+	GetWall(ctx context.Context, userId int, archived bool) ([]*core.ChatUser, error)
+	GetLastMessage(ctx context.Context, chatId int) (*core.ChatMessage, error)
 	CreateChat(ctx context.Context, req *core.Chat) error
-	GetMessagesByChatId(ctx context.Context, chatId int) ([]*core.ChatMessage, error)
+	GetMessagesByChatId(ctx context.Context, chatId, topicId int) ([]*core.ChatMessage, error)
 	JoinChat(ctx context.Context, req *core.ChatUser) error
 	LeaveChatGroup(ctx context.Context, req *core.ChatUser) error
 	DeleteChat(ctx context.Context, userId, chatId int) error
 	UpdateChatGroupAdmin(ctx context.Context, req *core.UpdateGroupChatAdminReq) error
 	UpdateChatGroupName(ctx context.Context, r *core.UpdateGroupChatNameReq) error
 	GetChatById(ctx context.Context, chatId int) (*core.Chat, error)
+	GetChatByHandle(ctx context.Context, handle string) (*core.Chat, error)
+	CountChatSubscribers(ctx context.Context, chatId, adminId int) (int64, error)
+	GetChatUser(ctx context.Context, userId, chatId int) (*core.ChatUser, error)
+	CountUsersOnChat(ctx context.Context, chatId int) (int64, error)
+	UpdateChatGroupDescription(ctx context.Context, req *core.UpdateGroupChatDescriptionReq) error
+	UpdateChatAvatar(ctx context.Context, chatId int, key string) error
+	UpdateChatSettings(ctx context.Context, chatId int, settings map[string]any) error
+	GetDirectChat(ctx context.Context, userId, peerId int) (*core.DirectChat, error)
+	CreateDirectChat(ctx context.Context, chat *core.Chat, direct *core.DirectChat) error
+	GetMessageById(ctx context.Context, messageId int) (*core.ChatMessage, error)
+	CreateJoinRequest(ctx context.Context, req *core.JoinRequest) error
+	GetJoinRequest(ctx context.Context, requestId int) (*core.JoinRequest, error)
+	GetJoinRequests(ctx context.Context, chatId int) ([]*core.JoinRequest, error)
+	DeleteJoinRequest(ctx context.Context, requestId int) error
+	ApproveJoinRequest(ctx context.Context, request *core.JoinRequest, maxMembers int, msg *core.ChatMessage) (bool, error)
+	LeaveChatAsAdmin(ctx context.Context, chatId, adminId, successorId int) (*core.ChatAdminLeftResp, error)
+	UpdateChatUserPrefs(ctx context.Context, userId, chatId int, prefs map[string]any) error
+	GetPinnedChats(ctx context.Context, userId int) ([]*core.ChatUser, error)
+	ReorderPinnedChats(ctx context.Context, userId int, chatIds []int) error
+	MarkMessagesRead(ctx context.Context, userId, chatId, messageId int) error
+	CountUnreadMessages(ctx context.Context, chatUser *core.ChatUser) (int64, error)
+	CreateChatFolder(ctx context.Context, folder *core.ChatFolder) error
+	GetChatFolders(ctx context.Context, userId int) ([]*core.ChatFolder, error)
+	GetChatFolder(ctx context.Context, folderId, userId int) (*core.ChatFolder, error)
+	UpdateChatFolder(ctx context.Context, folder *core.ChatFolder) error
+	DeleteChatFolder(ctx context.Context, folderId, userId int) error
+	ReorderChatFolders(ctx context.Context, userId int, folderIds []int) error
+	CreateChatTopic(ctx context.Context, topic *core.ChatTopic) error
+	GetChatTopic(ctx context.Context, topicId int) (*core.ChatTopic, error)
+	GetChatTopics(ctx context.Context, chatId int) ([]*core.ChatTopic, error)
+	EnableChatTopics(ctx context.Context, chatId int, general *core.ChatTopic) error
+	GetLastTopicMessage(ctx context.Context, topicId int) (*core.ChatMessage, error)
+	MarkTopicRead(ctx context.Context, userId, topicId, messageId int) error
+	CountUnreadTopicMessages(ctx context.Context, userId, topicId int) (int64, error)
+	SearchMessages(ctx context.Context, chatId, topicId int, text string, limit int) ([]*core.ChatMessage, error)
+	GetContactNames(ctx context.Context, userId int) (map[int]string, error)
+	IsBlocked(ctx context.Context, userId, blockedUserId int) (bool, error)
+	GetChatPeers(ctx context.Context, userId int) ([]int, error)
+	CheckPrivacy(ctx context.Context, ownerId, viewerId int, setting string) (bool, error)
+}
+
+type WSRepositoryREDIS interface {
+	SetCooldown(ctx context.Context, chatId, userId int, ttl time.Duration) (time.Duration, error)
+	ClearCooldown(ctx context.Context, chatId, userId int) error
+	SetOnline(ctx context.Context, userId int, ttl time.Duration) (bool, error)
+	RefreshOnline(ctx context.Context, userId int, ttl time.Duration) error
+	ExpireOnline(ctx context.Context, now time.Time, ttl time.Duration) ([]*core.PresenceResp, error)
+	SetOffline(ctx context.Context, userId int, lastSeen time.Time) (bool, error)
+	GetPresence(ctx context.Context, userIds []int) ([]*core.PresenceResp, error)
+}
+
+type WSRepositoryS3 interface {
+	GetAvatar(ctx context.Context, key string) (*v4.PresignedHTTPRequest, error)
+	UploadAvatar(ctx context.Context, file multipart.File, key string) error
+	DeleteAvatar(ctx context.Context, key string) error
 }
 
 type WebSocket struct {
-	psqlRepo WSRepositoryPSQL
+	psqlRepo  WSRepositoryPSQL
+	redisRepo WSRepositoryREDIS
+	s3Repo    WSRepositoryS3
+
+	avatarKeySalt string
 
 	log *logrus.Logger
 }
 
-func NewWebSocket(psqlRepo WSRepositoryPSQL, log *logrus.Logger) *WebSocket {
+func NewWebSocket(psqlRepo WSRepositoryPSQL, redisRepo WSRepositoryREDIS, s3Repo WSRepositoryS3, avatarKeySalt string, log *logrus.Logger) *WebSocket {
 	return &WebSocket{
-		psqlRepo: psqlRepo,
+		psqlRepo:  psqlRepo,
+		redisRepo: redisRepo,
+		s3Repo:    s3Repo,
+
+		avatarKeySalt: avatarKeySalt,
 
 		log: log,
 	}
@@ -66,39 +139,60 @@ func (ws *WebSocket) CreateChatGroup(ctx context.Context, req *core.CreateChatGr
 	return nil
 }
 
-func (ws *WebSocket) CreateChatDefault(ctx context.Context, req *core.CreateDefaultChatReq, userID int) error {
-	user, err := ws.psqlRepo.GetUserByPhone(ctx, req.Phone)
+// CreateChatDefault returns the direct chat between the two users,
+// creating it on the first call. The peer is found by username when
+// it is given and by phone otherwise.
+func (ws *WebSocket) CreateChatDefault(ctx context.Context, req *core.CreateDefaultChatReq, userID int) (int, error) {
+	var (
+		user *core.User
+		err  error
+	)
+
+	if req.Username != "" {
+		user, err = ws.psqlRepo.GetUserByUsername(ctx, req.Username)
+	} else {
+		user, err = ws.psqlRepo.GetUserByPhone(ctx, req.Phone)
+	}
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	chat := &core.Chat{
-		Type:      core.DefaultChatType,
-		CreatedAt: time.Now().Format(time.DateTime),
+	if user.ID == userID {
+		return 0, core.ErrCannotChatWithSelf
 	}
 
-	if err := ws.psqlRepo.CreateChat(ctx, chat); err != nil {
-		return err
+	blocked, err := ws.psqlRepo.IsBlocked(ctx, user.ID, userID)
+	if err != nil {
+		return 0, err
+	} else if blocked {
+		return 0, core.ErrBlockedByUser
 	}
 
-	if err := ws.psqlRepo.JoinChat(ctx, &core.ChatUser{
-		UserID: userID,
-		ChatID: chat.ID,
-	}); err != nil {
-		return err
+	return ws.getOrCreateDirectChat(ctx, &core.Chat{
+		Type: core.DefaultChatType,
+	}, userID, user.ID)
+}
+
+func (ws *WebSocket) JoinChatGroup(ctx context.Context, req *core.ChatUser) (*core.ChatMessage, error) {
+	chat, err := ws.psqlRepo.GetChatById(ctx, req.ChatID)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := ws.psqlRepo.JoinChat(ctx, &core.ChatUser{
-		UserID: user.ID,
-		ChatID: chat.ID,
-	}); err != nil {
-		return err
+	if chat.Type != core.GroupChatType {
+		return nil, core.ErrConnotJoinChat
 	}
 
-	return nil
+	if chat.JoinApproval {
+		return nil, core.ErrJoinApprovalRequired
+	}
+
+	return ws.joinChatGroup(ctx, req)
 }
 
-func (ws *WebSocket) JoinChatGroup(ctx context.Context, req *core.ChatUser) (*core.ChatMessage, error) {
+// AddChatGroupMember adds another user to the group. In groups with join
+// approval only the admin can add members directly.
+func (ws *WebSocket) AddChatGroupMember(ctx context.Context, req *core.AddChatGroupMemberReq, userId int) (*core.ChatMessage, error) {
 	chat, err := ws.psqlRepo.GetChatById(ctx, req.ChatID)
 	if err != nil {
 		return nil, err
@@ -108,6 +202,49 @@ func (ws *WebSocket) JoinChatGroup(ctx context.Context, req *core.ChatUser) (*co
 		return nil, core.ErrConnotJoinChat
 	}
 
+	isMember, err := ws.IsMember(ctx, userId, chat.ID)
+	if err != nil {
+		return nil, err
+	} else if !isMember {
+		return nil, core.ErrNotChatMember
+	}
+
+	if chat.JoinApproval && chat.AdminID != userId {
+		return nil, core.ErrJoinApprovalRequired
+	}
+
+	if _, err := ws.psqlRepo.GetUserById(ctx, req.UserID); err != nil {
+		return nil, err
+	}
+
+	blocked, err := ws.psqlRepo.IsBlocked(ctx, req.UserID, userId)
+	if err != nil {
+		return nil, err
+	} else if blocked {
+		return nil, core.ErrBlockedByUser
+	}
+
+	allowed, err := ws.psqlRepo.CheckPrivacy(ctx, req.UserID, userId, core.PrivacyGroupAdd)
+	if err != nil {
+		return nil, err
+	} else if !allowed {
+		return nil, core.ErrPrivacyRestricted
+	}
+
+	isMember, err = ws.IsMember(ctx, req.UserID, chat.ID)
+	if err != nil {
+		return nil, err
+	} else if isMember {
+		return nil, core.ErrJoinIsAlready
+	}
+
+	return ws.joinChatGroup(ctx, &core.ChatUser{
+		UserID: req.UserID,
+		ChatID: chat.ID,
+	})
+}
+
+func (ws *WebSocket) joinChatGroup(ctx context.Context, req *core.ChatUser) (*core.ChatMessage, error) {
 	usersOnChat, err := ws.psqlRepo.GetUserOnChat(ctx, req.ChatID)
 	if err != nil {
 		return nil, err
@@ -125,19 +262,11 @@ func (ws *WebSocket) JoinChatGroup(ctx context.Context, req *core.ChatUser) (*co
 		return nil, err
 	}
 
-	user, err := ws.psqlRepo.GetUserById(ctx, req.UserID)
+	msg, err := ws.joinMessage(ctx, req.UserID, req.ChatID)
 	if err != nil {
 		return nil, err
 	}
 
-	msg := &core.ChatMessage{
-		Username:  user.Username,
-		UserID:    user.ID,
-		ChatID:    req.ChatID,
-		Text:      fmt.Sprintf("%s joined the chat", user.Username),
-		CreatedAt: time.Now().Format(time.DateTime),
-	}
-
 	if err = ws.psqlRepo.SaveMessage(ctx, msg); err != nil {
 		return nil, err
 	}
@@ -145,25 +274,110 @@ func (ws *WebSocket) JoinChatGroup(ctx context.Context, req *core.ChatUser) (*co
 	return msg, nil
 }
 
+// joinMessage builds the message announcing that the user joined the chat.
+func (ws *WebSocket) joinMessage(ctx context.Context, userId, chatId int) (*core.ChatMessage, error) {
+	user, err := ws.psqlRepo.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.ChatMessage{
+		Username:  user.Username,
+		UserID:    user.ID,
+		ChatID:    chatId,
+		Text:      fmt.Sprintf("%s joined the chat", user.Username),
+		CreatedAt: time.Now().Format(time.DateTime),
+	}, nil
+}
+
 func (ws *WebSocket) GetUserOnChat(ctx context.Context, chatId int) ([]*core.ChatUser, error) {
 	return ws.psqlRepo.GetUserOnChat(ctx, chatId)
 }
 
 func (ws *WebSocket) SendMessage(ctx context.Context, req *core.SendMessageReq, userId int) (*core.ChatMessage, error) {
+	return ws.sendMessage(ctx, req, userId, "")
+}
+
+func (ws *WebSocket) sendMessage(ctx context.Context, req *core.SendMessageReq, userId int, forwardedFrom string) (*core.ChatMessage, error) {
+	chat, err := ws.psqlRepo.GetChatById(ctx, req.ChatID)
+	if err != nil {
+		return nil, err
+	}
+
+	isMember, err := ws.IsMember(ctx, userId, chat.ID)
+	if err != nil {
+		return nil, err
+	} else if !isMember {
+		return nil, core.ErrNotChatMember
+	}
+
+	if err := ws.messageTopic(ctx, chat, req.TopicID); err != nil {
+		return nil, err
+	}
+
+	if chat.Type == core.DefaultChatType {
+		if err := ws.checkDirectBlock(ctx, chat.ID, userId); err != nil {
+			return nil, err
+		}
+	}
+
+	// admins are exempt from posting restrictions
+	if chat.AdminID != userId {
+		if chat.Type == core.ChannelChatType || chat.OnlyAdminsPost {
+			return nil, core.ErrOnlyAdminCanPost
+		}
+	}
+
 	user, err := ws.psqlRepo.GetUserById(ctx, userId)
 	if err != nil {
 		return nil, err
 	}
 
 	msg := &core.ChatMessage{
-		Username:  user.Username,
-		UserID:    user.ID,
-		ChatID:    req.ChatID,
-		Text:      req.Text,
-		CreatedAt: time.Now().Format(time.DateTime),
+		Username:      user.Username,
+		UserID:        user.ID,
+		ChatID:        req.ChatID,
+		TopicID:       req.TopicID,
+		Text:          req.Text,
+		ForwardedFrom: forwardedFrom,
+		CreatedAt:     time.Now().Format(time.DateTime),
+	}
+
+	// channel posts are published on behalf of the channel, the admin is
+	// only identified when signatures are enabled
+	if chat.Type == core.ChannelChatType {
+		msg.Username = chat.Name
+
+		if chat.SignedPosts {
+			msg.Signature = user.Username
+		} else {
+			msg.UserID = 0
+		}
+	}
+
+	// the cooldown is started right before saving so a rejected message does
+	// not start it, and it is cleared again when the message is not saved
+	slowMode := chat.AdminID != userId && chat.SlowModeSeconds > 0
+	if slowMode {
+		remaining, err := ws.redisRepo.SetCooldown(ctx, chat.ID, userId, time.Duration(chat.SlowModeSeconds)*time.Second)
+		if err != nil {
+			return nil, err
+		} else if remaining > 0 {
+			return nil, &core.SlowModeError{Remaining: remaining}
+		}
 	}
 
 	if err = ws.psqlRepo.SaveMessage(ctx, msg); err != nil {
+		if slowMode {
+			if err := ws.redisRepo.ClearCooldown(ctx, chat.ID, userId); err != nil {
+				ws.log.Error("Error when clearing slow mode cooldown: ", err)
+			}
+		}
+
+		return nil, err
+	}
+
+	if err = ws.markRead(ctx, userId, chat.ID, msg.TopicID, msg.ID); err != nil {
 		return nil, err
 	}
 
@@ -171,10 +385,32 @@ func (ws *WebSocket) SendMessage(ctx context.Context, req *core.SendMessageReq,
 }
 
 func (ws *WebSocket) DeleteChat(ctx context.Context, userId, chatId int) error {
-	return ws.psqlRepo.DeleteChat(ctx, userId, chatId)
+	chat, err := ws.psqlRepo.GetChatById(ctx, chatId)
+	if err != nil {
+		return err
+	}
+
+	if err := ws.psqlRepo.DeleteChat(ctx, userId, chatId); err != nil {
+		return err
+	}
+
+	if chat.AvatarKey != "" {
+		return ws.s3Repo.DeleteAvatar(ctx, chat.AvatarKey)
+	}
+
+	return nil
 }
 
 func (ws *WebSocket) LeaveChatGroup(ctx context.Context, req *core.ChatUser) (*core.ChatMessage, error) {
+	chat, err := ws.psqlRepo.GetChatById(ctx, req.ChatID)
+	if err != nil {
+		return nil, err
+	}
+
+	if chat.Type != core.GroupChatType {
+		return nil, core.ErrInvalideChatID
+	}
+
 	if err := ws.psqlRepo.LeaveChatGroup(ctx, req); err != nil {
 		return nil, err
 	}
@@ -258,105 +494,61 @@ func (ws *WebSocket) IsAdmin(ctx context.Context, userId, chatId int) (bool, err
 	return chat.AdminID == userId, nil
 }
 
-func (ws *WebSocket) GetMessages(ctx context.Context, chatId int) ([]*core.ChatMessage, error) {
-	return ws.psqlRepo.GetMessagesByChatId(ctx, chatId)
-}
+// GetMessages returns the chat history and marks it as read for members.
+// A non zero topicId limits the history to that topic.
+func (ws *WebSocket) GetMessages(ctx context.Context, chatId, topicId, userId int) ([]*core.ChatMessage, error) {
+	if topicId != 0 {
+		if err := ws.checkTopic(ctx, chatId, topicId); err != nil {
+			return nil, err
+		}
+	}
 
-func (ws *WebSocket) GetWall(ctx context.Context, userId int) ([]*core.WallChatResp, error) {
-	wallChats, err := ws.psqlRepo.GetWall(ctx, userId)
+	messages, err := ws.psqlRepo.GetMessagesByChatId(ctx, chatId, topicId)
 	if err != nil {
 		return nil, err
 	}
 
-	var response []*core.WallChatResp
-	for _, wallChat := range wallChats {
-		if wallChat.Type == core.DefaultChatType {
-			messages, err := ws.psqlRepo.GetMessagesByChatId(ctx, wallChat.ID)
-			if err != nil {
-				return nil, err
-			} else if len(messages) == 0 {
-				usersOnChat, err := ws.GetUserOnChat(ctx, wallChat.ID)
-				if err != nil {
-					return nil, err
-				}
-
-				for _, userOnChat := range usersOnChat {
-					if userOnChat.UserID != userId {
-						user, err := ws.psqlRepo.GetUserById(ctx, userOnChat.UserID)
-						if err != nil {
-							return nil, err
-						}
-
-						response = append(response, &core.WallChatResp{
-							ChatID: wallChat.ID,
-							Name:   user.Username,
-						})
-					}
-				}
-
-				continue
-			}
-
-			usersOnChat, err := ws.GetUserOnChat(ctx, wallChat.ID)
-			if err != nil {
-				return nil, err
-			}
-
-			for _, userOnChat := range usersOnChat {
-				if userOnChat.UserID != userId {
-					user, err := ws.psqlRepo.GetUserById(ctx, userOnChat.UserID)
-					if err != nil {
-						return nil, err
-					}
-
-					if messages[len(messages)-1].UserID == userOnChat.UserID {
-						response = append(response, &core.WallChatResp{
-							ChatID:      wallChat.ID,
-							Name:        user.Username,
-							LastMessage: messages[len(messages)-1].Text,
-						})
-
-					}
-
-					response = append(response, &core.WallChatResp{
-						ChatID:      wallChat.ID,
-						Name:        user.Username,
-						LastMessage: fmt.Sprintf("%s: %s", messages[len(messages)-1].Username, messages[len(messages)-1].Text),
-					})
-				}
-			}
+	if len(messages) == 0 {
+		return messages, nil
+	}
 
-			continue
+	isMember, err := ws.IsMember(ctx, userId, chatId)
+	if err != nil {
+		return nil, err
+	} else if isMember {
+		lastId := 0
+		for _, message := range messages {
+			lastId = max(lastId, message.ID)
 		}
 
-		messages, err := ws.psqlRepo.GetMessagesByChatId(ctx, wallChat.ID)
-		if err != nil {
+		if err := ws.markRead(ctx, userId, chatId, topicId, lastId); err != nil {
 			return nil, err
-		} else if len(messages) == 0 {
-			response = append(response, &core.WallChatResp{
-				ChatID: wallChat.ID,
-				Name:   wallChat.Name,
-			})
-
-			continue
 		}
+	}
+
+	if err := ws.applyContactNames(ctx, chatId, userId, messages); err != nil {
+		return nil, err
+	}
 
-		if messages[len(messages)-1].UserID == userId {
-			response = append(response, &core.WallChatResp{
-				ChatID:      wallChat.ID,
-				Name:        wallChat.Name,
-				LastMessage: messages[len(messages)-1].Text,
-			})
+	return messages, nil
+}
 
-			continue
-		}
+func (ws *WebSocket) ReadMessages(ctx context.Context, req *core.ReadMessagesReq, userId int) error {
+	message, err := ws.psqlRepo.GetMessageById(ctx, req.MessageID)
+	if err != nil {
+		return err
+	}
+
+	if message.ChatID != req.ChatID {
+		return core.ErrMessageNotFound
+	}
 
-		response = append(response, &core.WallChatResp{
-			ChatID:      wallChat.ID,
-			Name:        wallChat.Name,
-			LastMessage: fmt.Sprintf("%s: %s", messages[len(messages)-1].Username, messages[len(messages)-1].Text),
-		})
+	isMember, err := ws.IsMember(ctx, userId, req.ChatID)
+	if err != nil {
+		return err
+	} else if !isMember {
+		return core.ErrNotChatMember
 	}
 
-	return response, nil
+	return ws.markRead(ctx, userId, req.ChatID, message.TopicID, req.MessageID)
 }
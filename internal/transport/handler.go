@@ -38,6 +38,9 @@ func (a *Api) InitApi() *mux.Router {
 		a.rest.InitRouter(api)
 	}
 
+	// public keys verifying the access tokens
+	r.HandleFunc("/.well-known/jwks.json", a.rest.JWKS).Methods(http.MethodGet)
+
 	// swagger
 	r.PathPrefix("/swagger").Handler(httpSwagger.Handler(
 		httpSwagger.URL(fmt.Sprintf("http://%s/swagger/doc.json", a.swaggerAddr)),
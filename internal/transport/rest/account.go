@@ -0,0 +1,310 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) initAccountRouter(api *mux.Router) {
+	account := api.PathPrefix("/account").Subrouter()
+	{
+		account.Use(h.AuthMiddleware)
+
+		account.HandleFunc("/export", h.accountExport).Methods(http.MethodGet)
+
+		deletion := account.PathPrefix("/delete").Subrouter()
+		{
+			deletion.HandleFunc("/request", h.accountDeleteRequest).Methods(http.MethodPost)
+			deletion.HandleFunc("/confirm", h.accountDeleteConfirm).Methods(http.MethodPost)
+			deletion.HandleFunc("/cancel", h.accountDeleteCancel).Methods(http.MethodPost)
+		}
+
+		phone := account.PathPrefix("/phone").Subrouter()
+		{
+			phone.HandleFunc("/request", h.accountPhoneRequest).Methods(http.MethodPost)
+			phone.HandleFunc("/confirm", h.accountPhoneConfirm).Methods(http.MethodPost)
+		}
+	}
+}
+
+// @Summary RequestAccountDeletion
+// @Tags Account
+// @Security ApiKeyAuth
+// @Description send the code confirming the account deletion to the phone of the user
+// @ID requestAccountDeletion
+// @Produce json
+// @Success 200
+// @Failure 400,500 {object} errorResponse
+// @Router /api/account/delete/request [post]
+func (h *Handler) accountDeleteRequest(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	if err := h.accountService.RequestDeletion(r.Context(), userId); err != nil {
+		if errors.Is(err, core.ErrDeletionAlreadyScheduled) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+// @Summary ConfirmAccountDeletion
+// @Tags Account
+// @Security ApiKeyAuth
+// @Description confirm the account deletion with the code, the account is deleted after a grace period unless the deletion is cancelled
+// @ID confirmAccountDeletion
+// @Accept json
+// @Produce json
+// @Param input body core.ConfirmAccountDeletionReq true "confirmation code"
+// @Success 200 {object} core.AccountDeletionResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/account/delete/confirm [post]
+func (h *Handler) accountDeleteConfirm(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.ConfirmAccountDeletionReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	resp, err := h.accountService.ConfirmDeletion(r.Context(), req, userId)
+	if err != nil {
+		if errors.Is(err, core.ErrCodeNotFound) || errors.Is(err, core.ErrDeletionAlreadyScheduled) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, resp)
+}
+
+// @Summary CancelAccountDeletion
+// @Tags Account
+// @Security ApiKeyAuth
+// @Description cancel the scheduled account deletion
+// @ID cancelAccountDeletion
+// @Produce json
+// @Success 200
+// @Failure 400,500 {object} errorResponse
+// @Router /api/account/delete/cancel [post]
+func (h *Handler) accountDeleteCancel(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	if err := h.accountService.CancelDeletion(r.Context(), userId); err != nil {
+		if errors.Is(err, core.ErrDeletionNotScheduled) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+// @Summary ExportAccountData
+// @Tags Account
+// @Security ApiKeyAuth
+// @Description download a zip archive with the profile, sessions, chat memberships and messages of the user
+// @ID exportAccountData
+// @Produce octet-stream
+// @Success 200 {file} file
+// @Failure 400,500 {object} errorResponse
+// @Router /api/account/export [get]
+func (h *Handler) accountExport(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	archive, err := h.accountService.ExportData(r.Context(), userId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newFileResponse(w, "account-export.zip", archive)
+}
+
+// @Summary RequestPhoneChange
+// @Tags Account
+// @Security ApiKeyAuth
+// @Description send a code to the new phone and, with verify_old, another one to the current phone
+// @ID requestPhoneChange
+// @Accept json
+// @Produce json
+// @Param input body core.ChangePhoneReq true "new phone"
+// @Success 200
+// @Failure 400,429,500 {object} errorResponse
+// @Router /api/account/phone/request [post]
+func (h *Handler) accountPhoneRequest(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.ChangePhoneReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	if err := h.accountService.RequestPhoneChange(r.Context(), req, userId); err != nil {
+		if errors.Is(err, core.ErrSamePhone) || errors.Is(err, core.ErrThisCredIsAlready) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if errors.Is(err, core.ErrCodeSentTooOften) {
+			h.newErrorResponse(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+// @Summary ConfirmPhoneChange
+// @Tags Account
+// @Security ApiKeyAuth
+// @Description confirm the phone change with the codes, every other session is logged out and contacts holding the old phone learn the new one
+// @ID confirmPhoneChange
+// @Accept json
+// @Produce json
+// @Param input body core.ConfirmPhoneChangeReq true "confirmation codes"
+// @Success 200 {object} core.PhoneChangedResp
+// @Failure 400,429,500 {object} errorResponse
+// @Router /api/account/phone/confirm [post]
+func (h *Handler) accountPhoneConfirm(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	sessionId, _ := r.Context().Value("sessionId").(int)
+
+	var req *core.ConfirmPhoneChangeReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	resp, revoked, err := h.accountService.ConfirmPhoneChange(r.Context(), req, userId, sessionId)
+	if err != nil {
+		if errors.Is(err, core.ErrPhoneChangeNotFound) || errors.Is(err, core.ErrInvalidPhoneCode) ||
+			errors.Is(err, core.ErrThisCredIsAlready) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		if errors.Is(err, core.ErrTooManyAttempts) {
+			h.newErrorResponse(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := h.authService.RevokeSessionTokens(r.Context(), revoked...); err != nil {
+		h.log.Error("Error when revoking session tokens: ", err)
+	}
+
+	h.wsHandler.StopSessionStream(userId, revoked...)
+
+	h.notifyPhoneChanged(r.Context(), resp)
+
+	h.newResponse(w, http.StatusOK, resp)
+}
+
+// notifyPhoneChanged tells the contacts holding the old phone about the new
+// one, as long as the privacy settings let them see it.
+func (h *Handler) notifyPhoneChanged(ctx context.Context, change *core.PhoneChangedResp) {
+	watcherIds, err := h.accountService.GetPhoneChangeWatchers(ctx, change.UserID)
+	if err != nil {
+		h.log.Error("Error when getting phone change watchers: ", err)
+		return
+	}
+
+	for _, watcherId := range watcherIds {
+		h.sendEvent(&core.Event{
+			Header:        core.PhoneChangedHeader,
+			Payload:       change,
+			Silent:        true,
+			ReceiveUserID: watcherId,
+		})
+	}
+}
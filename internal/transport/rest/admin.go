@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+// @Summary LeaveChatGroupAdmin
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description leave group as admin, ownership goes to the successor or to the longest-standing member
+// @ID leaveChatGroupAdmin
+// @Accept json
+// @Produce json
+// @Param input body core.LeaveChatGroupAdminReq true "leave chat group as admin"
+// @Success 200 {object} core.ChatAdminLeftResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/group/admin/leave [post]
+func (h *Handler) wsLeaveChatGroupAdmin(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.LeaveChatGroupAdminReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	resp, msg, err := h.wsService.LeaveChatGroupAsAdmin(r.Context(), req, userId)
+	switch err {
+	case nil:
+	case core.ErrNotAdmin, core.ErrSuccessorNotMember, core.ErrInvalideChatID:
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	case core.ErrRecordNotFound:
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrInvalideChatID.Error())
+		return
+	default:
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if !resp.ChatDeleted {
+		chatUsers, err := h.wsService.GetUserOnChat(r.Context(), req.ChatID)
+		if err != nil {
+			h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		for _, chatUser := range chatUsers {
+			h.sendEvent(&core.Event{
+				Header:        core.AdminLeftChatGroupHeader,
+				Message:       msg,
+				Payload:       resp,
+				ReceiveUserID: chatUser.UserID,
+			})
+		}
+	}
+
+	h.newResponse(w, http.StatusOK, resp)
+}
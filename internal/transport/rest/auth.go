@@ -17,8 +17,16 @@ func (h *Handler) initAuthRouter(r *mux.Router) {
 	{
 		api.HandleFunc("/register", h.authRegister).Methods(http.MethodPost)
 		api.HandleFunc("/login", h.authLogin).Methods(http.MethodPost)
-		api.HandleFunc("/verify/{code}", h.authVerify).Methods(http.MethodGet)
+		api.HandleFunc("/verify", h.authVerify).Methods(http.MethodPost)
 		api.HandleFunc("/refresh", h.authRefresh).Methods(http.MethodPost)
+
+		logout := api.PathPrefix("/logout").Subrouter()
+		{
+			logout.Use(h.AuthMiddleware)
+
+			logout.HandleFunc("", h.authLogout).Methods(http.MethodPost)
+			logout.HandleFunc("/all", h.authLogoutAll).Methods(http.MethodPost)
+		}
 	}
 }
 
@@ -68,7 +76,7 @@ func (h *Handler) authRegister(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param input body core.AuthLogin true "credentials"
 // @Success 200
-// @Failure 400,500 {object} errorResponse
+// @Failure 400,429,500 {object} errorResponse
 // @Router /api/auth/login [post]
 func (h *Handler) authLogin(w http.ResponseWriter, r *http.Request) {
 	var auth *core.AuthLogin
@@ -96,6 +104,11 @@ func (h *Handler) authLogin(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if errors.Is(err, core.ErrTooManyAttempts) || errors.Is(err, core.ErrCodeSentTooOften) {
+			h.newErrorResponse(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+
 		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -109,24 +122,44 @@ func (h *Handler) authLogin(w http.ResponseWriter, r *http.Request) {
 // @ID verify
 // @Accept  json
 // @Produce  json
-// @Param code path string true "code"
+// @Param input body core.AuthVerify true "phone and code"
+// @Param X-Device-Name header string false "device name shown in the session list"
+// @Param X-Device-Platform header string false "device platform shown in the session list"
 // @Success 200 {object} tokenResponse
-// @Failure 400,500 {object} errorResponse
-// @Router /api/auth/verify/{code} [get]
+// @Failure 400,429,500 {object} errorResponse
+// @Router /api/auth/verify [post]
 func (h *Handler) authVerify(w http.ResponseWriter, r *http.Request) {
-	code, err := getCodeFromRequest(r)
+	var req *core.AuthVerify
+	reqBytes, err := io.ReadAll(r.Body)
 	if err != nil {
 		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	tokens, err := h.authService.Verify(r.Context(), code)
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	tokens, err := h.authService.Verify(r.Context(), req, h.getSessionInfo(r))
 	if err != nil {
 		if errors.Is(err, core.ErrCodeNotFound) {
 			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
 			return
 		}
 
+		if errors.Is(err, core.ErrTooManyAttempts) {
+			h.newErrorResponse(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+
 		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -151,7 +184,7 @@ func (h *Handler) authVerify(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} tokenResponse
-// @Failure 400,500 {object} errorResponse
+// @Failure 400,401,500 {object} errorResponse
 // @Router /api/auth/refresh [post]
 func (h *Handler) authRefresh(w http.ResponseWriter, r *http.Request) {
 	cookie, err := r.Cookie("Authorization")
@@ -166,9 +199,15 @@ func (h *Handler) authRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokens, err := h.authService.Refresh(r.Context(), refreshToken)
+	tokens, revoked, err := h.authService.Refresh(r.Context(), refreshToken, h.getSessionInfo(r))
 	if err != nil {
-		if errors.Is(err, core.ErrRefreshTokenNotFound) {
+		if errors.Is(err, core.ErrRefreshTokenReused) {
+			h.wsHandler.StopSessionStream(revoked.UserID, revoked.ID)
+			h.newErrorResponse(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if errors.Is(err, core.ErrRefreshTokenNotFound) || errors.Is(err, core.ErrRefreshTokenIsExpired) {
 			h.newErrorResponse(w, http.StatusUnauthorized, err.Error())
 			return
 		}
@@ -177,27 +216,98 @@ func (h *Handler) authRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "Authorization",
-		Value:    "Bearer " + tokens[0],
-		HttpOnly: true,
-		MaxAge:   3600,
-		Path:     "/",
-	})
+	// a concurrent refresh already set the new refresh token
+	if tokens[0] != "" {
+		http.SetCookie(w, &http.Cookie{
+			Name:     "Authorization",
+			Value:    "Bearer " + tokens[0],
+			HttpOnly: true,
+			MaxAge:   3600,
+			Path:     "/",
+		})
+	}
 
 	h.newResponse(w, http.StatusOK, tokenResponse{
 		Token: tokens[1],
 	})
 }
 
-func getCodeFromRequest(r *http.Request) (string, error) {
-	vars := mux.Vars(r)
-	code := vars["code"]
-	if code == "" {
-		return "", core.ErrEmptyCode
+// @Summary Logout
+// @Tags Auth
+// @Security ApiKeyAuth
+// @Description end the current session, its refresh token and access token stop working right away
+// @ID logout
+// @Produce json
+// @Success 200
+// @Failure 400,401,500 {object} errorResponse
+// @Router /api/auth/logout [post]
+func (h *Handler) authLogout(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	sessionId, _ := r.Context().Value("sessionId").(int)
+
+	// the middleware already checked the header
+	accessToken := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	var refreshToken string
+	if cookie, err := r.Cookie("Authorization"); err == nil {
+		refreshToken, _ = getTokenFromCookie(cookie.Value)
+	}
+
+	if err := h.authService.Logout(r.Context(), accessToken, refreshToken); err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if sessionId != 0 {
+		h.wsHandler.StopSessionStream(userId, sessionId)
+	}
+
+	clearRefreshCookie(w)
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+// @Summary LogoutAll
+// @Tags Auth
+// @Security ApiKeyAuth
+// @Description end every session of the user, all refresh and access tokens issued so far stop working right away
+// @ID logoutAll
+// @Produce json
+// @Success 200
+// @Failure 400,401,500 {object} errorResponse
+// @Router /api/auth/logout/all [post]
+func (h *Handler) authLogoutAll(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
 	}
 
-	return code, nil
+	if _, err := h.authService.LogoutAll(r.Context(), userId); err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.wsHandler.StopStream(userId)
+
+	clearRefreshCookie(w)
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+func clearRefreshCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     "Authorization",
+		Value:    "",
+		HttpOnly: true,
+		MaxAge:   -1,
+		Path:     "/",
+	})
 }
 
 func getTokenFromCookie(cookieValue string) (string, error) {
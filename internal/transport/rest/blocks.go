@@ -0,0 +1,148 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) initBlocksRouter(api *mux.Router) {
+	blocks := api.PathPrefix("/blocks").Subrouter()
+	{
+		blocks.Use(h.AuthMiddleware)
+
+		blocks.HandleFunc("/block", h.blocksBlock).Methods(http.MethodPost)
+		blocks.HandleFunc("/get", h.blocksGet).Methods(http.MethodGet)
+		blocks.HandleFunc("/unblock/{userId}", h.blocksUnblock).Methods(http.MethodDelete)
+	}
+}
+
+// @Summary BlockUser
+// @Tags Blocks
+// @Security ApiKeyAuth
+// @Description block user
+// @ID blockUser
+// @Accept json
+// @Produce json
+// @Param input body core.BlockUserReq true "user to block"
+// @Success 200
+// @Failure 400,404,500 {object} errorResponse
+// @Router /api/blocks/block [post]
+func (h *Handler) blocksBlock(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.BlockUserReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	if err := h.contactsService.BlockUser(r.Context(), req, userId); err != nil {
+		if errors.Is(err, core.ErrCannotBlockSelf) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		} else if errors.Is(err, core.ErrUserNotFound) {
+			h.newErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+// @Summary GetBlockedUsers
+// @Tags Blocks
+// @Security ApiKeyAuth
+// @Description get blocked users, recently blocked first
+// @ID getBlockedUsers
+// @Produce json
+// @Success 200 {array} core.BlockedUserResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/blocks/get [get]
+func (h *Handler) blocksGet(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	blocked, err := h.contactsService.GetBlockedUsers(r.Context(), userId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, blocked)
+}
+
+// @Summary UnblockUser
+// @Tags Blocks
+// @Security ApiKeyAuth
+// @Description unblock user
+// @ID unblockUser
+// @Produce json
+// @Param userId path string true "user id"
+// @Success 200
+// @Failure 400,500 {object} errorResponse
+// @Router /api/blocks/unblock/{userId} [delete]
+func (h *Handler) blocksUnblock(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	blockedUserId, err := getUserIdFromRequest(r)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.contactsService.UnblockUser(r.Context(), userId, blockedUserId); err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+func getUserIdFromRequest(r *http.Request) (int, error) {
+	vars := mux.Vars(r)
+	userId := vars["userId"]
+	if userId == "" {
+		return 0, core.ErrEmptyUserID
+	}
+
+	userIdInt, err := strconv.Atoi(userId)
+	if err != nil {
+		return 0, err
+	}
+
+	return userIdInt, nil
+}
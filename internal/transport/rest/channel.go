@@ -0,0 +1,204 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) initChannelRouter(chat *mux.Router) {
+	channel := chat.PathPrefix("/channel").Subrouter()
+	{
+		channel.HandleFunc("/create", h.wsCreateChannel).Methods(http.MethodPost)
+		channel.HandleFunc("/join", h.wsJoinChannel).Methods(http.MethodPost)
+		channel.HandleFunc("/leave/{chatId}", h.wsLeaveChannel).Methods(http.MethodDelete)
+		channel.HandleFunc("/subscribers/{chatId}", h.wsChannelSubscribers).Methods(http.MethodGet)
+
+		admin := channel.PathPrefix("/admin").Subrouter()
+		{
+			admin.HandleFunc("/delete/{chatId}", h.wsDeleteChatGroup).Methods(http.MethodDelete)
+		}
+	}
+}
+
+// @Summary CreateChannel
+// @Tags Channel
+// @Security ApiKeyAuth
+// @Description create broadcast channel
+// @ID createChannel
+// @Accept json
+// @Produce json
+// @Param input body core.CreateChannelReq true "create channel"
+// @Success 200 {object} core.ChatIDResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/channel/create [post]
+func (h *Handler) wsCreateChannel(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.CreateChannelReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	chatId, err := h.wsService.CreateChannel(r.Context(), req, userId)
+	if err != nil {
+		if errors.Is(err, core.ErrHandleIsTaken) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, core.ChatIDResp{
+		ChatID: chatId,
+	})
+}
+
+// @Summary JoinChannel
+// @Tags Channel
+// @Security ApiKeyAuth
+// @Description subscribe to channel by handle
+// @ID joinChannel
+// @Accept json
+// @Produce json
+// @Param input body core.JoinChannelReq true "channel handle"
+// @Success 200 {object} core.ChatIDResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/channel/join [post]
+func (h *Handler) wsJoinChannel(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.JoinChannelReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	chatId, err := h.wsService.JoinChannel(r.Context(), req, userId)
+	switch err {
+	case nil:
+		h.newResponse(w, http.StatusOK, core.ChatIDResp{
+			ChatID: chatId,
+		})
+		return
+	case core.ErrChannelNotFound, core.ErrConnotJoinChat:
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	default:
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+}
+
+// @Summary LeaveChannel
+// @Tags Channel
+// @Security ApiKeyAuth
+// @Description unsubscribe from channel
+// @ID leaveChannel
+// @Produce json
+// @Param chatId path string true "chat id"
+// @Success 200
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/channel/leave/{chatId} [delete]
+func (h *Handler) wsLeaveChannel(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	chatId, err := getChatIdFromRequest(r)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = h.wsService.LeaveChannel(r.Context(), &core.ChatUser{
+		UserID: userId,
+		ChatID: chatId,
+	})
+	switch err {
+	case nil:
+		h.newResponse(w, http.StatusOK, nil)
+		return
+	case core.ErrAdminCannnotLeave, core.ErrInvalideChatID:
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	default:
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+}
+
+// @Summary ChannelSubscribers
+// @Tags Channel
+// @Security ApiKeyAuth
+// @Description get channel subscriber count
+// @ID channelSubscribers
+// @Produce json
+// @Param chatId path string true "chat id"
+// @Success 200 {object} core.ChannelSubscribersResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/channel/subscribers/{chatId} [get]
+func (h *Handler) wsChannelSubscribers(w http.ResponseWriter, r *http.Request) {
+	chatId, err := getChatIdFromRequest(r)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	response, err := h.wsService.GetChannelSubscribers(r.Context(), chatId)
+	if err != nil {
+		if errors.Is(err, core.ErrInvalideChatID) || errors.Is(err, core.ErrRecordNotFound) {
+			h.newErrorResponse(w, http.StatusBadRequest, core.ErrInvalideChatID.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, response)
+}
@@ -0,0 +1,317 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+// @Summary ChatInfo
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description get chat info
+// @ID chatInfo
+// @Produce json
+// @Param chatId path string true "chat id"
+// @Success 200 {object} core.ChatInfoResp
+// @Failure 400,403,500 {object} errorResponse
+// @Router /api/chat/{chatId} [get]
+func (h *Handler) wsChatInfo(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	chatId, err := getChatIdFromRequest(r)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	info, err := h.wsService.GetChatInfo(r.Context(), chatId)
+	if err != nil {
+		if errors.Is(err, core.ErrInvalideChatID) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// channels are public, everything else is visible to members only
+	if info.Type != core.ChannelChatType {
+		ok, err = h.wsService.IsMember(r.Context(), userId, chatId)
+		if err != nil {
+			h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		} else if !ok {
+			h.newErrorResponse(w, http.StatusForbidden, core.ErrNotChatMember.Error())
+			return
+		}
+	}
+
+	h.newResponse(w, http.StatusOK, info.ForViewer(userId))
+}
+
+// @Summary UpdateChatGroupDescription
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description update chat group description
+// @ID updateChatGroupDescription
+// @Accept json
+// @Produce json
+// @Param input body core.UpdateGroupChatDescriptionReq true "update chat group description"
+// @Success 200 {object} core.ChatInfoResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/group/admin/update/description [put]
+func (h *Handler) wsUpdateChatGroupDescription(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.UpdateGroupChatDescriptionReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	ok, err = h.wsService.IsAdmin(r.Context(), userId, req.ChatID)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrNotAdmin.Error())
+		return
+	}
+
+	info, err := h.wsService.UpdateChatGroupDescription(r.Context(), req)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	chatUsers, err := h.wsService.GetUserOnChat(r.Context(), req.ChatID)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.notifyChatMetadataUpdated(chatUsers, info)
+
+	h.newResponse(w, http.StatusOK, info)
+}
+
+// @Summary UpdateChatGroupSettings
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description update group or channel settings
+// @ID updateChatGroupSettings
+// @Accept json
+// @Produce json
+// @Param input body core.UpdateGroupChatSettingsReq true "chat settings"
+// @Success 200 {object} core.ChatInfoResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/group/admin/update/settings [put]
+func (h *Handler) wsUpdateChatGroupSettings(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.UpdateGroupChatSettingsReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	ok, err = h.wsService.IsAdmin(r.Context(), userId, req.ChatID)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrNotAdmin.Error())
+		return
+	}
+
+	info, err := h.wsService.UpdateChatGroupSettings(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, core.ErrTopicsGroupOnly) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	chatUsers, err := h.wsService.GetUserOnChat(r.Context(), req.ChatID)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.notifyChatMetadataUpdated(chatUsers, info)
+
+	h.newResponse(w, http.StatusOK, info)
+}
+
+// @Summary UploadChatAvatar
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description upload group or channel avatar
+// @ID uploadChatAvatar
+// @Produce json
+// @Param chatId path string true "chat id"
+// @Param avatar formData file true "avatar"
+// @Success 200 {object} core.ChatInfoResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/group/admin/avatar/upload/{chatId} [post]
+func (h *Handler) wsUploadChatAvatar(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	chatId, err := getChatIdFromRequest(r)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ok, err = h.wsService.IsAdmin(r.Context(), userId, chatId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrNotAdmin.Error())
+		return
+	}
+
+	file, _, err := r.FormFile("avatar")
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer file.Close()
+
+	info, err := h.wsService.UploadChatAvatar(r.Context(), file, chatId)
+	if err != nil {
+		if errors.Is(err, core.ErrInvalideChatID) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	chatUsers, err := h.wsService.GetUserOnChat(r.Context(), chatId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.notifyChatMetadataUpdated(chatUsers, info)
+
+	h.newResponse(w, http.StatusOK, info)
+}
+
+// @Summary DeleteChatAvatar
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description delete group or channel avatar
+// @ID deleteChatAvatar
+// @Produce json
+// @Param chatId path string true "chat id"
+// @Success 200 {object} core.ChatInfoResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/group/admin/avatar/delete/{chatId} [delete]
+func (h *Handler) wsDeleteChatAvatar(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	chatId, err := getChatIdFromRequest(r)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ok, err = h.wsService.IsAdmin(r.Context(), userId, chatId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrNotAdmin.Error())
+		return
+	}
+
+	info, err := h.wsService.DeleteChatAvatar(r.Context(), chatId)
+	if err != nil {
+		if errors.Is(err, core.ErrAvatarNotFound) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	chatUsers, err := h.wsService.GetUserOnChat(r.Context(), chatId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.notifyChatMetadataUpdated(chatUsers, info)
+
+	h.newResponse(w, http.StatusOK, info)
+}
+
+func (h *Handler) notifyChatMetadataUpdated(chatUsers []*core.ChatUser, info *core.ChatInfoResp) {
+	for _, chatUser := range chatUsers {
+		h.sendEvent(&core.Event{
+			Header:        core.ChatMetadataUpdatedHeader,
+			Payload:       info.ForViewer(chatUser.UserID),
+			ReceiveUserID: chatUser.UserID,
+		})
+	}
+}
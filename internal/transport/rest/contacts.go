@@ -0,0 +1,195 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) initContactsRouter(api *mux.Router) {
+	contacts := api.PathPrefix("/contacts").Subrouter()
+	{
+		contacts.Use(h.AuthMiddleware)
+
+		contacts.HandleFunc("/salt", h.contactsSalt).Methods(http.MethodGet)
+		contacts.HandleFunc("/sync", h.contactsSync).Methods(http.MethodPost)
+		contacts.HandleFunc("/get", h.contactsGet).Methods(http.MethodGet)
+		contacts.HandleFunc("/update", h.contactsUpdate).Methods(http.MethodPut)
+		contacts.HandleFunc("/delete/{userId}", h.contactsDelete).Methods(http.MethodDelete)
+	}
+}
+
+// @Summary ContactsSalt
+// @Tags Contacts
+// @Security ApiKeyAuth
+// @Description get the salt for hashing phone numbers before sync
+// @ID contactsSalt
+// @Produce json
+// @Success 200 {object} core.ContactsSaltResp
+// @Router /api/contacts/salt [get]
+func (h *Handler) contactsSalt(w http.ResponseWriter, r *http.Request) {
+	h.newResponse(w, http.StatusOK, h.contactsService.GetSalt())
+}
+
+// @Summary SyncContacts
+// @Tags Contacts
+// @Security ApiKeyAuth
+// @Description upload address book as phone numbers or salted hashes, registered users are added to contacts
+// @ID syncContacts
+// @Accept json
+// @Produce json
+// @Param input body core.SyncContactsReq true "address book"
+// @Success 200 {array} core.ContactResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/contacts/sync [post]
+func (h *Handler) contactsSync(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.SyncContactsReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	contacts, err := h.contactsService.SyncContacts(r.Context(), req, userId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, contacts)
+}
+
+// @Summary GetContacts
+// @Tags Contacts
+// @Security ApiKeyAuth
+// @Description get contacts
+// @ID getContacts
+// @Produce json
+// @Success 200 {array} core.ContactResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/contacts/get [get]
+func (h *Handler) contactsGet(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	contacts, err := h.contactsService.GetContacts(r.Context(), userId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, contacts)
+}
+
+// @Summary UpdateContact
+// @Tags Contacts
+// @Security ApiKeyAuth
+// @Description set custom contact name, empty name resets it
+// @ID updateContact
+// @Accept json
+// @Produce json
+// @Param input body core.UpdateContactReq true "contact name"
+// @Success 200 {object} core.ContactResp
+// @Failure 400,404,500 {object} errorResponse
+// @Router /api/contacts/update [put]
+func (h *Handler) contactsUpdate(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.UpdateContactReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	contact, err := h.contactsService.UpdateContact(r.Context(), req, userId)
+	if err != nil {
+		if errors.Is(err, core.ErrContactNotFound) {
+			h.newErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, contact)
+}
+
+// @Summary DeleteContact
+// @Tags Contacts
+// @Security ApiKeyAuth
+// @Description delete contact
+// @ID deleteContact
+// @Produce json
+// @Param userId path string true "contact user id"
+// @Success 200
+// @Failure 400,404,500 {object} errorResponse
+// @Router /api/contacts/delete/{userId} [delete]
+func (h *Handler) contactsDelete(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	contactUserId, err := getUserIdFromRequest(r)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.contactsService.DeleteContact(r.Context(), userId, contactUserId); err != nil {
+		if errors.Is(err, core.ErrContactNotFound) {
+			h.newErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,247 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+
+	"github.com/gorilla/mux"
+)
+
+// @Summary CreateChatFolder
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description create chat folder
+// @ID createChatFolder
+// @Accept json
+// @Produce json
+// @Param input body core.ChatFolderReq true "folder"
+// @Success 201 {object} core.ChatFolderResp
+// @Failure 400,403,500 {object} errorResponse
+// @Router /api/chat/folder/create [post]
+func (h *Handler) wsCreateChatFolder(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.ChatFolderReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	response, err := h.wsService.CreateChatFolder(r.Context(), req, userId)
+	if err != nil {
+		if errors.Is(err, core.ErrTooManyFolders) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		} else if errors.Is(err, core.ErrNotChatMember) {
+			h.newErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusCreated, response)
+}
+
+// @Summary GetChatFolders
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description get chat folders in their order
+// @ID getChatFolders
+// @Produce json
+// @Success 200 {array} core.ChatFolderResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/folder/get [get]
+func (h *Handler) wsGetChatFolders(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	response, err := h.wsService.GetChatFolders(r.Context(), userId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, response)
+}
+
+// @Summary UpdateChatFolder
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description update chat folder name, chats and rules
+// @ID updateChatFolder
+// @Accept json
+// @Produce json
+// @Param input body core.UpdateChatFolderReq true "folder"
+// @Success 200 {object} core.ChatFolderResp
+// @Failure 400,403,404,500 {object} errorResponse
+// @Router /api/chat/folder/update [put]
+func (h *Handler) wsUpdateChatFolder(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.UpdateChatFolderReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	response, err := h.wsService.UpdateChatFolder(r.Context(), req, userId)
+	if err != nil {
+		if errors.Is(err, core.ErrFolderNotFound) {
+			h.newErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		} else if errors.Is(err, core.ErrNotChatMember) {
+			h.newErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, response)
+}
+
+// @Summary DeleteChatFolder
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description delete chat folder, chats stay on the wall
+// @ID deleteChatFolder
+// @Produce json
+// @Param folderId path string true "folder id"
+// @Success 200
+// @Failure 400,404,500 {object} errorResponse
+// @Router /api/chat/folder/delete/{folderId} [delete]
+func (h *Handler) wsDeleteChatFolder(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	folderId, err := getFolderIdFromRequest(r)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.wsService.DeleteChatFolder(r.Context(), folderId, userId); err != nil {
+		if errors.Is(err, core.ErrFolderNotFound) {
+			h.newErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+// @Summary ReorderChatFolders
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description reorder chat folders
+// @ID reorderChatFolders
+// @Accept json
+// @Produce json
+// @Param input body core.ReorderChatFoldersReq true "folder ids in the new order"
+// @Success 200
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/folder/reorder [put]
+func (h *Handler) wsReorderChatFolders(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.ReorderChatFoldersReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	if err := h.wsService.ReorderChatFolders(r.Context(), req, userId); err != nil {
+		if errors.Is(err, core.ErrInvalidFolderOrder) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+func getFolderIdFromRequest(r *http.Request) (int, error) {
+	vars := mux.Vars(r)
+	folderId := vars["folderId"]
+	if folderId == "" {
+		return 0, core.ErrEmptyFolderID
+	}
+
+	folderIdInt, err := strconv.Atoi(folderId)
+	if err != nil {
+		return 0, err
+	}
+
+	return folderIdInt, nil
+}
@@ -3,9 +3,11 @@ package rest
 import (
 	"context"
 	"mime/multipart"
+	"net"
 	"net/http"
 
 	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+	"github.com/Woodfyn/chat-api-backend-go/pkg/token"
 
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
@@ -19,51 +21,129 @@ type Encoder interface {
 type Auth interface {
 	Register(ctx context.Context, user *core.AuthRegister) error
 	Login(ctx context.Context, auth *core.AuthLogin) error
-	Refresh(ctx context.Context, refreshToken string) ([]string, error)
-	Verify(ctx context.Context, code string) ([]string, error)
-	ParseToken(token string) (string, error)
+	Refresh(ctx context.Context, refreshToken string, info *core.SessionInfo) ([]string, *core.Token, error)
+	Verify(ctx context.Context, req *core.AuthVerify, info *core.SessionInfo) ([]string, error)
+	ParseToken(accessToken string) (*token.Claims, error)
 	IsTokenExpired(accessToken string) bool
+	IsTokenRevoked(ctx context.Context, claims *token.Claims) (bool, error)
+	Logout(ctx context.Context, accessToken, refreshToken string) error
+	LogoutAll(ctx context.Context, userId int) ([]int, error)
+	RevokeSessionTokens(ctx context.Context, sessionIds ...int) error
+	JWKS() *token.JWKS
+	GetSessions(ctx context.Context, userId, currentSessionId int) ([]*core.SessionResp, error)
+	RevokeSession(ctx context.Context, userId, sessionId int) error
+	RevokeOtherSessions(ctx context.Context, userId, currentSessionId int) ([]int, error)
 }
 
 type Profile interface {
 	GetProfile(ctx context.Context, userId int) (*core.GetProfileResp, error)
+	GetUserProfile(ctx context.Context, userId, viewerId int) (*core.UserProfileResp, error)
+	GetUserProfileByUsername(ctx context.Context, username string, viewerId int) (*core.UserProfileResp, error)
+	SearchUsers(ctx context.Context, req *core.SearchUsersReq, viewerId int) ([]*core.UserSearchResp, error)
+	GetPrivacy(ctx context.Context, userId int) ([]*core.PrivacySettingResp, error)
+	UpdatePrivacy(ctx context.Context, req *core.UpdatePrivacyReq, userId int) (*core.PrivacySettingResp, error)
 	UpdateProfile(ctx context.Context, user *core.User) error
+	UpdateProfileDetails(ctx context.Context, userId int, req *core.UpdateProfileDetailsReq) error
+	GetProfileUpdate(ctx context.Context, userId int) (map[int]*core.UserProfileResp, error)
 	GetAvatars(ctx context.Context, userId int) ([]*core.GetAllUserAvatarsResp, error)
 	UploadAvatar(ctx context.Context, file multipart.File, userId int) error
 	DeleteAvatar(ctx context.Context, userId int, avatarId int) error
+	SetCurrentAvatar(ctx context.Context, req *core.SetCurrentAvatarReq, userId int) error
+	ReorderAvatars(ctx context.Context, req *core.ReorderAvatarsReq, userId int) error
+}
+
+type Contacts interface {
+	GetSalt() *core.ContactsSaltResp
+	SyncContacts(ctx context.Context, req *core.SyncContactsReq, userId int) ([]*core.ContactResp, error)
+	GetContacts(ctx context.Context, userId int) ([]*core.ContactResp, error)
+	UpdateContact(ctx context.Context, req *core.UpdateContactReq, userId int) (*core.ContactResp, error)
+	DeleteContact(ctx context.Context, userId, contactUserId int) error
+	BlockUser(ctx context.Context, req *core.BlockUserReq, userId int) error
+	UnblockUser(ctx context.Context, userId, blockedUserId int) error
+	GetBlockedUsers(ctx context.Context, userId int) ([]*core.BlockedUserResp, error)
+}
+
+type Account interface {
+	RequestDeletion(ctx context.Context, userId int) error
+	ConfirmDeletion(ctx context.Context, req *core.ConfirmAccountDeletionReq, userId int) (*core.AccountDeletionResp, error)
+	CancelDeletion(ctx context.Context, userId int) error
+	ExportData(ctx context.Context, userId int) ([]byte, error)
+	RequestPhoneChange(ctx context.Context, req *core.ChangePhoneReq, userId int) error
+	ConfirmPhoneChange(ctx context.Context, req *core.ConfirmPhoneChangeReq, userId, sessionId int) (*core.PhoneChangedResp, []int, error)
+	GetPhoneChangeWatchers(ctx context.Context, userId int) ([]int, error)
 }
 
 type WebSocket interface {
 	CreateChatGroup(ctx context.Context, req *core.CreateChatGroupReq, adminID int) error
 	LeaveChatGroup(ctx context.Context, req *core.ChatUser) (*core.ChatMessage, error)
 	JoinChatGroup(ctx context.Context, req *core.ChatUser) (*core.ChatMessage, error)
+	AddChatGroupMember(ctx context.Context, req *core.AddChatGroupMemberReq, userId int) (*core.ChatMessage, error)
 	UpdateChatGroupName(ctx context.Context, req *core.UpdateGroupChatNameReq, userId int) (*core.ChatMessage, error)
 	UpdateChatGroupAdmin(ctx context.Context, req *core.UpdateGroupChatAdminReq, userId int) (*core.ChatMessage, error)
-	CreateChatDefault(ctx context.Context, req *core.CreateDefaultChatReq, userID int) error
+	CreateChatDefault(ctx context.Context, req *core.CreateDefaultChatReq, userID int) (int, error)
+	GetSavedChat(ctx context.Context, userId int) (int, error)
+	ForwardMessage(ctx context.Context, req *core.ForwardMessageReq, userId int) (*core.ChatMessage, error)
 	DeleteChat(ctx context.Context, userId, chatId int) error
 	GetUserOnChat(ctx context.Context, chatId int) ([]*core.ChatUser, error)
-	GetWall(ctx context.Context, userId int) ([]*core.WallChatResp, error)
+	GetWall(ctx context.Context, userId int, archived bool, folderId int) ([]*core.WallChatResp, error)
 	SendMessage(ctx context.Context, req *core.SendMessageReq, userId int) (*core.ChatMessage, error)
-	GetMessages(ctx context.Context, chatId int) ([]*core.ChatMessage, error)
+	GetMessages(ctx context.Context, chatId, topicId, userId int) ([]*core.ChatMessage, error)
+	ReadMessages(ctx context.Context, req *core.ReadMessagesReq, userId int) error
 	IsAdmin(ctx context.Context, userId int, chatId int) (bool, error)
+	CreateChannel(ctx context.Context, req *core.CreateChannelReq, adminID int) (int, error)
+	JoinChannel(ctx context.Context, req *core.JoinChannelReq, userId int) (int, error)
+	LeaveChannel(ctx context.Context, req *core.ChatUser) error
+	GetChannelSubscribers(ctx context.Context, chatId int) (*core.ChannelSubscribersResp, error)
+	IsMember(ctx context.Context, userId, chatId int) (bool, error)
+	GetChatInfo(ctx context.Context, chatId int) (*core.ChatInfoResp, error)
+	UpdateChatGroupDescription(ctx context.Context, req *core.UpdateGroupChatDescriptionReq) (*core.ChatInfoResp, error)
+	UploadChatAvatar(ctx context.Context, file multipart.File, chatId int) (*core.ChatInfoResp, error)
+	DeleteChatAvatar(ctx context.Context, chatId int) (*core.ChatInfoResp, error)
+	UpdateChatGroupSettings(ctx context.Context, req *core.UpdateGroupChatSettingsReq) (*core.ChatInfoResp, error)
+	CreateJoinRequest(ctx context.Context, req *core.ChatUser) (*core.JoinRequestResp, error)
+	GetJoinRequests(ctx context.Context, chatId int) ([]*core.JoinRequestResp, error)
+	ApproveJoinRequest(ctx context.Context, requestId, adminId int) (*core.JoinRequestResp, *core.ChatMessage, error)
+	DeclineJoinRequest(ctx context.Context, requestId, adminId int) (*core.JoinRequestResp, error)
+	LeaveChatGroupAsAdmin(ctx context.Context, req *core.LeaveChatGroupAdminReq, userId int) (*core.ChatAdminLeftResp, *core.ChatMessage, error)
+	UpdateChatPrefs(ctx context.Context, req *core.UpdateChatPrefsReq, userId int) error
+	ReorderPinnedChats(ctx context.Context, req *core.ReorderPinnedChatsReq, userId int) error
+	SetOnline(ctx context.Context, userId int) (*core.PresenceResp, error)
+	RefreshOnline(ctx context.Context, userId int) error
+	ExpirePresence(ctx context.Context) ([]*core.PresenceResp, error)
+	SetOffline(ctx context.Context, userId int) (*core.PresenceResp, error)
+	GetPresence(ctx context.Context, req *core.PresenceReq, viewerId int) ([]*core.PresenceResp, error)
+	GetPresenceWatchers(ctx context.Context, userId int) ([]int, error)
+	CreateChatFolder(ctx context.Context, req *core.ChatFolderReq, userId int) (*core.ChatFolderResp, error)
+	GetChatFolders(ctx context.Context, userId int) ([]*core.ChatFolderResp, error)
+	UpdateChatFolder(ctx context.Context, req *core.UpdateChatFolderReq, userId int) (*core.ChatFolderResp, error)
+	DeleteChatFolder(ctx context.Context, folderId, userId int) error
+	ReorderChatFolders(ctx context.Context, req *core.ReorderChatFoldersReq, userId int) error
+	CreateChatTopic(ctx context.Context, req *core.CreateChatTopicReq) (*core.ChatTopicResp, error)
+	GetChatTopics(ctx context.Context, chatId, userId int) ([]*core.ChatTopicResp, error)
+	SearchMessages(ctx context.Context, chatId, topicId, userId int, text string) ([]*core.ChatMessage, error)
 }
 
 type WebSocketHandler interface {
-	Stream(w http.ResponseWriter, r *http.Request, userId int)
+	Stream(w http.ResponseWriter, r *http.Request, userId, sessionId int)
 	StopStream(userId int)
+	StopSessionStream(userId int, sessionIds ...int)
 	OnlineStream(userId int) bool
 	AddEvent(userId int, event *core.Event)
 }
 
 type Handler struct {
-	authService    Auth
-	profileService Profile
-	wsService      WebSocket
+	authService     Auth
+	profileService  Profile
+	wsService       WebSocket
+	contactsService Contacts
+	accountService  Account
 
 	encoder Encoder
 
 	wsHandler WebSocketHandler
 
+	trustedProxies []*net.IPNet
+
 	log *logrus.Logger
 }
 
@@ -71,24 +151,32 @@ type Deps struct {
 	Auth      Auth
 	Profile   Profile
 	WebSocket WebSocket
+	Contacts  Contacts
+	Account   Account
 
 	Encoder Encoder
 
 	WebSocketHandler WebSocketHandler
 
+	TrustedProxies []*net.IPNet
+
 	Log *logrus.Logger
 }
 
 func NewHandler(deps Deps) *Handler {
 	return &Handler{
-		authService:    deps.Auth,
-		profileService: deps.Profile,
-		wsService:      deps.WebSocket,
+		authService:     deps.Auth,
+		profileService:  deps.Profile,
+		wsService:       deps.WebSocket,
+		contactsService: deps.Contacts,
+		accountService:  deps.Account,
 
 		encoder: deps.Encoder,
 
 		wsHandler: deps.WebSocketHandler,
 
+		trustedProxies: deps.TrustedProxies,
+
 		log: deps.Log,
 	}
 }
@@ -98,6 +186,11 @@ func (h *Handler) InitRouter(api *mux.Router) *mux.Router {
 	h.initProfileRouter(api)
 	h.initStreamRouter(api)
 	h.initWebSocketRouter(api)
+	h.initContactsRouter(api)
+	h.initBlocksRouter(api)
+	h.initPresenceRouter(api)
+	h.initAccountRouter(api)
+	h.initSessionsRouter(api)
 
 	return api
 }
@@ -0,0 +1,210 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+// wsRequestJoinChatGroup is used by wsJoinChatGroup when the group
+// requires admin approval: it stores a pending request and lets the admin know.
+func (h *Handler) wsRequestJoinChatGroup(w http.ResponseWriter, r *http.Request, req *core.ChatUser) {
+	request, err := h.wsService.CreateJoinRequest(r.Context(), req)
+	switch err {
+	case nil:
+	case core.ErrJoinIsAlready:
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	default:
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	info, err := h.wsService.GetChatInfo(r.Context(), req.ChatID)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.sendEvent(&core.Event{
+		Header:        core.JoinRequestedHeader,
+		Payload:       request,
+		ReceiveUserID: info.AdminID,
+	})
+
+	h.newResponse(w, http.StatusAccepted, request)
+}
+
+// @Summary GetJoinRequests
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description get pending join requests of a group
+// @ID getJoinRequests
+// @Produce json
+// @Param chatId path string true "chat id"
+// @Success 200 {array} core.JoinRequestResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/group/admin/requests/get/{chatId} [get]
+func (h *Handler) wsGetJoinRequests(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	chatId, err := getChatIdFromRequest(r)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ok, err = h.wsService.IsAdmin(r.Context(), userId, chatId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrNotAdmin.Error())
+		return
+	}
+
+	response, err := h.wsService.GetJoinRequests(r.Context(), chatId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, response)
+}
+
+// @Summary ApproveJoinRequest
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description approve pending join request
+// @ID approveJoinRequest
+// @Accept json
+// @Produce json
+// @Param input body core.JoinRequestDecisionReq true "join request"
+// @Success 200
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/group/admin/requests/approve [post]
+func (h *Handler) wsApproveJoinRequest(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.JoinRequestDecisionReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	request, msg, err := h.wsService.ApproveJoinRequest(r.Context(), req.RequestID, userId)
+	switch err {
+	case nil:
+	case core.ErrJoinRequestNotFound, core.ErrNotAdmin, core.ErrChatGroupFull, core.ErrInvalideChatID:
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	default:
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// nil msg means the requester had already joined on their own
+	if msg != nil {
+		chatUsers, err := h.wsService.GetUserOnChat(r.Context(), request.ChatID)
+		if err != nil {
+			h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		for _, chatUser := range chatUsers {
+			h.sendEvent(&core.Event{
+				Header:        core.JoinChatEventHeader,
+				Message:       msg,
+				ReceiveUserID: chatUser.UserID,
+			})
+		}
+	}
+
+	h.sendEvent(&core.Event{
+		Header:        core.JoinRequestApprovedHeader,
+		Payload:       request,
+		ReceiveUserID: request.UserID,
+	})
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+// @Summary DeclineJoinRequest
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description decline pending join request
+// @ID declineJoinRequest
+// @Accept json
+// @Produce json
+// @Param input body core.JoinRequestDecisionReq true "join request"
+// @Success 200
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/group/admin/requests/decline [post]
+func (h *Handler) wsDeclineJoinRequest(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.JoinRequestDecisionReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	request, err := h.wsService.DeclineJoinRequest(r.Context(), req.RequestID, userId)
+	switch err {
+	case nil:
+	case core.ErrJoinRequestNotFound, core.ErrNotAdmin:
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	default:
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.sendEvent(&core.Event{
+		Header:        core.JoinRequestDeclinedHeader,
+		Payload:       request,
+		ReceiveUserID: request.UserID,
+	})
+
+	h.newResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,18 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// JWKS serves the public keys verifying the access tokens. It lives outside
+// of /api and its body is plain json, so other services can read it.
+func (h *Handler) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	w.Header().Add("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(h.authService.JWKS()); err != nil {
+		h.log.Error("Error when writing jwks: ", err)
+	}
+}
@@ -0,0 +1,146 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+// @Summary ForwardMessage
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description forward message to another chat, e.g. to saved messages
+// @ID forwardMessage
+// @Accept json
+// @Produce json
+// @Param input body core.ForwardMessageReq true "message to forward"
+// @Success 200
+// @Failure 400,403,429,500 {object} errorResponse
+// @Router /api/chat/message/forward [post]
+func (h *Handler) wsForwardMessage(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.ForwardMessageReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	msg, err := h.wsService.ForwardMessage(r.Context(), req, userId)
+	if err != nil {
+		if errors.Is(err, core.ErrMessageNotFound) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		} else if errors.Is(err, core.ErrOnlyAdminCanPost) || errors.Is(err, core.ErrNotChatMember) || errors.Is(err, core.ErrBlockedByUser) {
+			h.newErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		} else if isTopicError(err) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var slowModeErr *core.SlowModeError
+		if errors.As(err, &slowModeErr) {
+			w.Header().Set("Retry-After", strconv.Itoa(slowModeErr.Seconds()))
+			h.newErrorResponse(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	chatUsers, err := h.wsService.GetUserOnChat(r.Context(), req.ChatID)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	now := time.Now()
+
+	for _, chatUser := range chatUsers {
+		h.sendEvent(&core.Event{
+			Header:        core.NewMessageEventHeader,
+			Message:       msg,
+			Silent:        chatUser.IsMuted(now),
+			ReceiveUserID: chatUser.UserID,
+		})
+	}
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+// @Summary ReadMessages
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description mark messages of the chat as read up to the given message
+// @ID readMessages
+// @Accept json
+// @Produce json
+// @Param input body core.ReadMessagesReq true "last read message"
+// @Success 200
+// @Failure 400,403,500 {object} errorResponse
+// @Router /api/chat/message/read [post]
+func (h *Handler) wsReadMessages(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.ReadMessagesReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	if err := h.wsService.ReadMessages(r.Context(), req, userId); err != nil {
+		if errors.Is(err, core.ErrMessageNotFound) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		} else if errors.Is(err, core.ErrNotChatMember) {
+			h.newErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, nil)
+}
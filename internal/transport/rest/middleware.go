@@ -30,7 +30,7 @@ func (h *Handler) AuthMiddleware(handler http.Handler) http.Handler {
 			return
 		}
 
-		id, err := h.authService.ParseToken(headerParts[1])
+		claims, err := h.authService.ParseToken(headerParts[1])
 		if err != nil {
 			h.newErrorResponse(w, http.StatusUnauthorized, core.ErrInvalidAccessToken.Error())
 			return
@@ -41,13 +41,33 @@ func (h *Handler) AuthMiddleware(handler http.Handler) http.Handler {
 			return
 		}
 
-		idInt, err := strconv.Atoi(id)
+		revoked, err := h.authService.IsTokenRevoked(r.Context(), claims)
 		if err != nil {
 			h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
 			return
+		} else if revoked {
+			h.newErrorResponse(w, http.StatusUnauthorized, core.ErrRevokedAccessToken.Error())
+			return
+		}
+
+		idInt, err := strconv.Atoi(claims.UserID)
+		if err != nil {
+			h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		// access tokens issued before sessions were tracked have no session
+		sessionIdInt := 0
+		if claims.SessionID != "" {
+			sessionIdInt, err = strconv.Atoi(claims.SessionID)
+			if err != nil {
+				h.newErrorResponse(w, http.StatusUnauthorized, core.ErrInvalidAccessToken.Error())
+				return
+			}
 		}
 
 		ctx := context.WithValue(r.Context(), "userId", idInt)
+		ctx = context.WithValue(ctx, "sessionId", sessionIdInt)
 
 		handler.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -0,0 +1,110 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+// @Summary UpdateChatPrefs
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description archive, pin or mute chat for the current user
+// @ID updateChatPrefs
+// @Accept json
+// @Produce json
+// @Param input body core.UpdateChatPrefsReq true "chat preferences"
+// @Success 200
+// @Failure 400,403,500 {object} errorResponse
+// @Router /api/chat/prefs/update [put]
+func (h *Handler) wsUpdateChatPrefs(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.UpdateChatPrefsReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	if err := h.wsService.UpdateChatPrefs(r.Context(), req, userId); err != nil {
+		if errors.Is(err, core.ErrNotChatMember) {
+			h.newErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+// @Summary ReorderPinnedChats
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description reorder pinned chats on the wall
+// @ID reorderPinnedChats
+// @Accept json
+// @Produce json
+// @Param input body core.ReorderPinnedChatsReq true "pinned chat ids in the new order"
+// @Success 200
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/prefs/pins/reorder [put]
+func (h *Handler) wsReorderPinnedChats(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.ReorderPinnedChatsReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	if err := h.wsService.ReorderPinnedChats(r.Context(), req, userId); err != nil {
+		if errors.Is(err, core.ErrInvalidPinnedChats) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, nil)
+}
@@ -0,0 +1,159 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+
+	"github.com/gorilla/mux"
+)
+
+// presenceRefreshInterval has to stay well below the presence TTL of the
+// service.
+const presenceRefreshInterval = time.Minute
+
+func (h *Handler) initPresenceRouter(api *mux.Router) {
+	presence := api.PathPrefix("/presence").Subrouter()
+	{
+		presence.Use(h.AuthMiddleware)
+
+		presence.HandleFunc("/get", h.presenceGet).Methods(http.MethodPost)
+	}
+}
+
+// @Summary GetPresence
+// @Tags Presence
+// @Security ApiKeyAuth
+// @Description get online status and last seen time of users, hidden by their privacy settings are shown offline
+// @ID getPresence
+// @Accept json
+// @Produce json
+// @Param input body core.PresenceReq true "user ids"
+// @Success 200 {array} core.PresenceResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/presence/get [post]
+func (h *Handler) presenceGet(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.PresenceReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	presence, err := h.wsService.GetPresence(r.Context(), req, userId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, presence)
+}
+
+func (h *Handler) setOnline(ctx context.Context, userId int) {
+	presence, err := h.wsService.SetOnline(ctx, userId)
+	if err != nil {
+		h.log.Error("Error when setting user online: ", err)
+		return
+	}
+
+	h.notifyPresenceChanged(ctx, presence)
+}
+
+// keepOnline refreshes the presence of the user until done is closed, so
+// the user goes offline on their own when the instance holding the stream
+// dies.
+func (h *Handler) keepOnline(ctx context.Context, userId int, done <-chan struct{}) {
+	ticker := time.NewTicker(presenceRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if err := h.wsService.RefreshOnline(ctx, userId); err != nil {
+				h.log.Error("Error when refreshing user presence: ", err)
+			}
+		}
+	}
+}
+
+// RunPresenceExpiry announces every presenceRefreshInterval the users whose
+// streams died without closing as offline, until the context is done.
+func (h *Handler) RunPresenceExpiry(ctx context.Context) {
+	ticker := time.NewTicker(presenceRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		expired, err := h.wsService.ExpirePresence(ctx)
+		if err != nil {
+			h.log.Error("Error when expiring user presence: ", err)
+			continue
+		}
+
+		for _, presence := range expired {
+			h.notifyPresenceChanged(ctx, presence)
+		}
+	}
+}
+
+func (h *Handler) setOffline(ctx context.Context, userId int) {
+	presence, err := h.wsService.SetOffline(ctx, userId)
+	if err != nil {
+		h.log.Error("Error when setting user offline: ", err)
+		return
+	}
+
+	h.notifyPresenceChanged(ctx, presence)
+}
+
+// notifyPresenceChanged sends the new presence to everyone sharing a chat
+// with the user who may see it, nil presence means nothing has changed.
+func (h *Handler) notifyPresenceChanged(ctx context.Context, presence *core.PresenceResp) {
+	if presence == nil {
+		return
+	}
+
+	peerIds, err := h.wsService.GetPresenceWatchers(ctx, presence.UserID)
+	if err != nil {
+		h.log.Error("Error when getting presence watchers: ", err)
+		return
+	}
+
+	for _, peerId := range peerIds {
+		h.sendEvent(&core.Event{
+			Header:        core.PresenceChangedHeader,
+			Payload:       presence,
+			Silent:        true,
+			ReceiveUserID: peerId,
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+// @Summary GetPrivacy
+// @Tags Profile
+// @Security ApiKeyAuth
+// @Description get privacy settings
+// @ID getPrivacy
+// @Produce json
+// @Success 200 {array} core.PrivacySettingResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/profile/privacy/get [get]
+func (h *Handler) profilePrivacyGet(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	settings, err := h.profileService.GetPrivacy(r.Context(), userId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, settings)
+}
+
+// @Summary UpdatePrivacy
+// @Tags Profile
+// @Security ApiKeyAuth
+// @Description update privacy setting, exception lists replace the previous ones
+// @ID updatePrivacy
+// @Accept json
+// @Produce json
+// @Param input body core.UpdatePrivacyReq true "privacy setting"
+// @Success 200 {object} core.PrivacySettingResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/profile/privacy/update [put]
+func (h *Handler) profilePrivacyUpdate(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.UpdatePrivacyReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	setting, err := h.profileService.UpdatePrivacy(r.Context(), req, userId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, setting)
+}
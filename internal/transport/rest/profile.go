@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -18,13 +19,25 @@ func (h *Handler) initProfileRouter(api *mux.Router) {
 		profile.Use(h.AuthMiddleware)
 
 		profile.HandleFunc("/", h.profileGet).Methods(http.MethodGet)
+		profile.HandleFunc("/{userId:[0-9]+}", h.profileGetUser).Methods(http.MethodGet)
+		profile.HandleFunc("/username/{username}", h.profileGetByUsername).Methods(http.MethodGet)
+		profile.HandleFunc("/search", h.profileSearch).Methods(http.MethodGet)
 		profile.HandleFunc("/update", h.profileUpdate).Methods(http.MethodPatch)
+		profile.HandleFunc("/details", h.profileUpdateDetails).Methods(http.MethodPatch)
+
+		privacy := profile.PathPrefix("/privacy").Subrouter()
+		{
+			privacy.HandleFunc("/get", h.profilePrivacyGet).Methods(http.MethodGet)
+			privacy.HandleFunc("/update", h.profilePrivacyUpdate).Methods(http.MethodPut)
+		}
 
 		avatar := profile.PathPrefix("/avatar").Subrouter()
 		{
 			avatar.HandleFunc("/get", h.profileAvatarGetAll).Methods(http.MethodGet)
 			avatar.HandleFunc("/upload", h.profileAvatarUpload).Methods(http.MethodPost)
 			avatar.HandleFunc("/delete/{avatarId}", h.profileAvatarDelete).Methods(http.MethodDelete)
+			avatar.HandleFunc("/current", h.profileAvatarSetCurrent).Methods(http.MethodPut)
+			avatar.HandleFunc("/reorder", h.profileAvatarReorder).Methods(http.MethodPut)
 		}
 	}
 }
@@ -55,6 +68,124 @@ func (h *Handler) profileGet(w http.ResponseWriter, r *http.Request) {
 	h.newResponse(w, http.StatusOK, profile)
 }
 
+// @Summary GetUserProfile
+// @Tags Profile
+// @Security ApiKeyAuth
+// @Description get profile of another user
+// @ID getUserProfile
+// @Produce json
+// @Param userId path string true "user id"
+// @Success 200 {object} core.UserProfileResp
+// @Failure 400,404,500 {object} errorResponse
+// @Router /api/profile/{userId} [get]
+func (h *Handler) profileGetUser(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	profileUserId, err := getUserIdFromRequest(r)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	profile, err := h.profileService.GetUserProfile(r.Context(), profileUserId, userId)
+	if err != nil {
+		if errors.Is(err, core.ErrUserNotFound) {
+			h.newErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, profile)
+}
+
+// @Summary GetUserProfileByUsername
+// @Tags Profile
+// @Security ApiKeyAuth
+// @Description get profile of another user by username
+// @ID getUserProfileByUsername
+// @Produce json
+// @Param username path string true "username"
+// @Success 200 {object} core.UserProfileResp
+// @Failure 400,404,500 {object} errorResponse
+// @Router /api/profile/username/{username} [get]
+func (h *Handler) profileGetByUsername(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	profile, err := h.profileService.GetUserProfileByUsername(r.Context(), mux.Vars(r)["username"], userId)
+	if err != nil {
+		if errors.Is(err, core.ErrUserNotFound) {
+			h.newErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, profile)
+}
+
+// @Summary SearchUsers
+// @Tags Profile
+// @Security ApiKeyAuth
+// @Description search users by username prefix
+// @ID searchUsers
+// @Produce json
+// @Param query query string true "username prefix"
+// @Param limit query int false "max results, 20 by default"
+// @Success 200 {array} core.UserSearchResp
+// @Failure 400,429,500 {object} errorResponse
+// @Router /api/profile/search [get]
+func (h *Handler) profileSearch(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	req := &core.SearchUsersReq{
+		Query: r.URL.Query().Get("query"),
+	}
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		var err error
+		req.Limit, err = strconv.Atoi(limit)
+		if err != nil {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	users, err := h.profileService.SearchUsers(r.Context(), req, userId)
+	if err != nil {
+		if errors.Is(err, core.ErrTooManyRequests) {
+			h.newErrorResponse(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, users)
+}
+
 // @Summary UpdateProfile
 // @Tags Profile
 // @Security ApiKeyAuth
@@ -94,10 +225,9 @@ func (h *Handler) profileUpdate(w http.ResponseWriter, r *http.Request) {
 
 	if err = h.profileService.UpdateProfile(r.Context(), &core.User{
 		ID:       userId,
-		Phone:    req.Phone,
 		Username: req.Username,
 	}); err != nil {
-		if errors.Is(err, core.ErrDuplicatedKey) {
+		if errors.Is(err, core.ErrThisCredIsAlready) {
 			h.newErrorResponse(w, http.StatusBadRequest, core.ErrThisCredIsAlready.Error())
 			return
 		}
@@ -106,9 +236,82 @@ func (h *Handler) profileUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.notifyProfileUpdated(r.Context(), userId)
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+// @Summary UpdateProfileDetails
+// @Tags Profile
+// @Security ApiKeyAuth
+// @Description update display name, bio and status, only the fields that are set change and an empty string clears the field
+// @ID updateProfileDetails
+// @Accept json
+// @Produce json
+// @Param input body core.UpdateProfileDetailsReq true "profile details to update"
+// @Success 200
+// @Failure 400,500 {object} errorResponse
+// @Router /api/profile/details [patch]
+func (h *Handler) profileUpdateDetails(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req *core.UpdateProfileDetailsReq
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	if err := h.profileService.UpdateProfileDetails(r.Context(), userId, req); err != nil {
+		if errors.Is(err, core.ErrStatusExpired) || errors.Is(err, core.ErrStatusExpiryNoText) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.notifyProfileUpdated(r.Context(), userId)
+
 	h.newResponse(w, http.StatusOK, nil)
 }
 
+// notifyProfileUpdated sends the public profile of the user to everyone
+// sharing a chat with them.
+func (h *Handler) notifyProfileUpdated(ctx context.Context, userId int) {
+	updates, err := h.profileService.GetProfileUpdate(ctx, userId)
+	if err != nil {
+		h.log.Error("Error when getting profile update: ", err)
+		return
+	}
+
+	for peerId, profile := range updates {
+		h.sendEvent(&core.Event{
+			Header:        core.ProfileUpdatedHeader,
+			Payload:       profile,
+			Silent:        true,
+			ReceiveUserID: peerId,
+		})
+	}
+}
+
 // @Summary GetAvatars
 // @Tags Profile
 // @Security ApiKeyAuth
@@ -137,7 +340,7 @@ func (h *Handler) profileAvatarGetAll(w http.ResponseWriter, r *http.Request) {
 // @Summary UploadAvatar
 // @Tags Profile
 // @Security ApiKeyAuth
-// @Description upload avatar
+// @Description upload avatar, the image is cropped to a square and stored in 64, 256 and 640 pixel sizes
 // @ID uploadAvatar
 // @Produce json
 // @Param avatar formData file true "avatar"
@@ -160,10 +363,17 @@ func (h *Handler) profileAvatarUpload(w http.ResponseWriter, r *http.Request) {
 	defer file.Close()
 
 	if err := h.profileService.UploadAvatar(r.Context(), file, userId); err != nil {
+		if errors.Is(err, core.ErrInvalidImage) || errors.Is(err, core.ErrImageTooLarge) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	h.notifyProfileUpdated(r.Context(), userId)
+
 	h.newResponse(w, http.StatusOK, nil)
 }
 
@@ -206,6 +416,112 @@ func (h *Handler) profileAvatarDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.notifyProfileUpdated(r.Context(), userId)
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+// @Summary SetCurrentAvatar
+// @Tags Profile
+// @Security ApiKeyAuth
+// @Description choose the avatar shown on the profile
+// @ID setCurrentAvatar
+// @Accept json
+// @Produce json
+// @Param input body core.SetCurrentAvatarReq true "avatar id"
+// @Success 200
+// @Failure 400,500 {object} errorResponse
+// @Router /api/profile/avatar/current [put]
+func (h *Handler) profileAvatarSetCurrent(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.SetCurrentAvatarReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	if err := h.profileService.SetCurrentAvatar(r.Context(), req, userId); err != nil {
+		if errors.Is(err, core.ErrAvatarNotFound) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.notifyProfileUpdated(r.Context(), userId)
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+// @Summary ReorderAvatars
+// @Tags Profile
+// @Security ApiKeyAuth
+// @Description reorder the avatar gallery, the list must contain every avatar of the user
+// @ID reorderAvatars
+// @Accept json
+// @Produce json
+// @Param input body core.ReorderAvatarsReq true "avatar ids in the new order"
+// @Success 200
+// @Failure 400,500 {object} errorResponse
+// @Router /api/profile/avatar/reorder [put]
+func (h *Handler) profileAvatarReorder(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.ReorderAvatarsReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	if err := h.profileService.ReorderAvatars(r.Context(), req, userId); err != nil {
+		if errors.Is(err, core.ErrInvalidAvatarOrder) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.notifyProfileUpdated(r.Context(), userId)
+
 	h.newResponse(w, http.StatusOK, nil)
 }
 
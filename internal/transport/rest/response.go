@@ -3,6 +3,7 @@ package rest
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
 )
 
@@ -36,6 +37,26 @@ func (h *Handler) newResponse(w http.ResponseWriter, status int, input any) erro
 	return nil
 }
 
+// newFileResponse sends the file as an attachment, encrypted the same way
+// as every other response body.
+func (h *Handler) newFileResponse(w http.ResponseWriter, fileName string, data []byte) error {
+	encryptResp, err := h.encoder.Encrypt(data)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Add("Content-Type", "application/octet-stream")
+	w.Header().Add("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	w.WriteHeader(http.StatusOK)
+
+	_, err = w.Write([]byte(base64.StdEncoding.EncodeToString(encryptResp)))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 type errorResponse struct {
 	Error string `json:"error"`
 }
@@ -0,0 +1,225 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+
+	"github.com/gorilla/mux"
+)
+
+func (h *Handler) initSessionsRouter(api *mux.Router) {
+	sessions := api.PathPrefix("/sessions").Subrouter()
+	{
+		sessions.Use(h.AuthMiddleware)
+
+		sessions.HandleFunc("/get", h.sessionsGet).Methods(http.MethodGet)
+		sessions.HandleFunc("/revoke/{sessionId}", h.sessionsRevoke).Methods(http.MethodDelete)
+		sessions.HandleFunc("/revoke-others", h.sessionsRevokeOthers).Methods(http.MethodPost)
+	}
+}
+
+// @Summary GetSessions
+// @Tags Sessions
+// @Security ApiKeyAuth
+// @Description get active sessions of the user
+// @ID getSessions
+// @Produce json
+// @Success 200 {array} core.SessionResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/sessions/get [get]
+func (h *Handler) sessionsGet(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	sessionId, _ := r.Context().Value("sessionId").(int)
+
+	sessions, err := h.authService.GetSessions(r.Context(), userId, sessionId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, sessions)
+}
+
+// @Summary RevokeSession
+// @Tags Sessions
+// @Security ApiKeyAuth
+// @Description log out the session and close its stream
+// @ID revokeSession
+// @Produce json
+// @Param sessionId path int true "session id"
+// @Success 200
+// @Failure 400,404,500 {object} errorResponse
+// @Router /api/sessions/revoke/{sessionId} [delete]
+func (h *Handler) sessionsRevoke(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	sessionId, err := getSessionIdFromRequest(r)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.authService.RevokeSession(r.Context(), userId, sessionId); err != nil {
+		if errors.Is(err, core.ErrSessionNotFound) {
+			h.newErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.wsHandler.StopSessionStream(userId, sessionId)
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+// @Summary RevokeOtherSessions
+// @Tags Sessions
+// @Security ApiKeyAuth
+// @Description log out every session but the current one
+// @ID revokeOtherSessions
+// @Produce json
+// @Success 200
+// @Failure 400,500 {object} errorResponse
+// @Router /api/sessions/revoke-others [post]
+func (h *Handler) sessionsRevokeOthers(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	sessionId, _ := r.Context().Value("sessionId").(int)
+
+	revoked, err := h.authService.RevokeOtherSessions(r.Context(), userId, sessionId)
+	if err != nil {
+		if errors.Is(err, core.ErrEmptySessionID) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.wsHandler.StopSessionStream(userId, revoked...)
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
+func getSessionIdFromRequest(r *http.Request) (int, error) {
+	vars := mux.Vars(r)
+	sessionId := vars["sessionId"]
+	if sessionId == "" {
+		return 0, core.ErrEmptySessionID
+	}
+
+	sessionIdInt, err := strconv.Atoi(sessionId)
+	if err != nil {
+		return 0, err
+	}
+
+	return sessionIdInt, nil
+}
+
+// getSessionInfo describes the device of the request. Clients name the
+// device with the X-Device-Name and X-Device-Platform headers.
+func (h *Handler) getSessionInfo(r *http.Request) *core.SessionInfo {
+	return &core.SessionInfo{
+		DeviceName: r.Header.Get("X-Device-Name"),
+		Platform:   r.Header.Get("X-Device-Platform"),
+		IP:         h.getClientIP(r),
+		UserAgent:  r.UserAgent(),
+	}
+}
+
+// getClientIP returns the address of the connection. X-Forwarded-For is
+// only read when the connection comes from a trusted proxy, then the last
+// address not belonging to a trusted proxy is the client.
+func (h *Handler) getClientIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		ip = r.RemoteAddr
+	}
+
+	if !h.isTrustedProxy(ip) {
+		return ip
+	}
+
+	forwarded := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(forwarded[i])
+		if hop == "" {
+			continue
+		}
+
+		ip = hop
+		if !h.isTrustedProxy(ip) {
+			break
+		}
+	}
+
+	return ip
+}
+
+func (h *Handler) isTrustedProxy(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, proxy := range h.trustedProxies {
+		if proxy.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseTrustedProxies reads proxy addresses and CIDRs, a single address
+// stands for itself.
+func ParseTrustedProxies(proxies []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("trusted proxy %q: invalid address", proxy)
+			}
+
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", proxy, err)
+		}
+
+		nets = append(nets, ipNet)
+	}
+
+	return nets, nil
+}
@@ -32,7 +32,16 @@ func (h *Handler) wsStreamConnect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.wsHandler.Stream(w, r, userId)
+	sessionId, _ := r.Context().Value("sessionId").(int)
+
+	h.setOnline(r.Context(), userId)
+	defer h.setOffline(r.Context(), userId)
+
+	done := make(chan struct{})
+	defer close(done)
+	go h.keepOnline(r.Context(), userId, done)
+
+	h.wsHandler.Stream(w, r, userId, sessionId)
 
 	h.newResponse(w, http.StatusOK, "stream disconnected")
 }
@@ -56,3 +65,9 @@ func (h *Handler) wsStreamDisconnect(w http.ResponseWriter, r *http.Request) {
 
 	h.newResponse(w, http.StatusOK, nil)
 }
+
+// sendEvent delivers the event to its receiver if the receiver
+// currently holds an open stream, offline users are skipped.
+func (h *Handler) sendEvent(event *core.Event) {
+	h.wsHandler.AddEvent(event.ReceiveUserID, event)
+}
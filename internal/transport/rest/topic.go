@@ -0,0 +1,187 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/Woodfyn/chat-api-backend-go/internal/core"
+)
+
+// @Summary CreateChatTopic
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description create topic in a group in topics mode
+// @ID createChatTopic
+// @Accept json
+// @Produce json
+// @Param input body core.CreateChatTopicReq true "topic"
+// @Success 201 {object} core.ChatTopicResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/group/admin/topic/create [post]
+func (h *Handler) wsCreateChatTopic(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.CreateChatTopicReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	ok, err = h.wsService.IsAdmin(r.Context(), userId, req.ChatID)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	} else if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrNotAdmin.Error())
+		return
+	}
+
+	topic, err := h.wsService.CreateChatTopic(r.Context(), req)
+	if err != nil {
+		if errors.Is(err, core.ErrTopicsDisabled) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	chatUsers, err := h.wsService.GetUserOnChat(r.Context(), req.ChatID)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for _, chatUser := range chatUsers {
+		h.sendEvent(&core.Event{
+			Header:        core.TopicCreatedHeader,
+			Payload:       topic,
+			Silent:        true,
+			ReceiveUserID: chatUser.UserID,
+		})
+	}
+
+	h.newResponse(w, http.StatusCreated, topic)
+}
+
+// @Summary GetChatTopics
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description get topics of a group with last messages and unread counts
+// @ID getChatTopics
+// @Produce json
+// @Param chatId path string true "chat id"
+// @Success 200 {array} core.ChatTopicResp
+// @Failure 400,403,500 {object} errorResponse
+// @Router /api/chat/topic/get/{chatId} [get]
+func (h *Handler) wsGetChatTopics(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	chatId, err := getChatIdFromRequest(r)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	topics, err := h.wsService.GetChatTopics(r.Context(), chatId, userId)
+	if err != nil {
+		if errors.Is(err, core.ErrNotChatMember) {
+			h.newErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		}
+
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, topics)
+}
+
+// @Summary SearchMessages
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description search messages of a chat, newest first
+// @ID searchMessages
+// @Produce json
+// @Param chatId path string true "chat id"
+// @Param text query string true "text to search"
+// @Param topic_id query int false "search only in the topic"
+// @Success 200 {array} core.ChatMessage
+// @Failure 400,403,404,500 {object} errorResponse
+// @Router /api/chat/message/search/{chatId} [get]
+func (h *Handler) wsSearchMessages(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	chatId, err := getChatIdFromRequest(r)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	topicId, err := getTopicIdFromQuery(r)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	messages, err := h.wsService.SearchMessages(r.Context(), chatId, topicId, userId, r.URL.Query().Get("text"))
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrEmptySearchText):
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		case errors.Is(err, core.ErrNotChatMember):
+			h.newErrorResponse(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, core.ErrTopicNotFound):
+			h.newErrorResponse(w, http.StatusNotFound, err.Error())
+		default:
+			h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, messages)
+}
+
+func getTopicIdFromQuery(r *http.Request) (int, error) {
+	topicId := r.URL.Query().Get("topic_id")
+	if topicId == "" {
+		return 0, nil
+	}
+
+	return strconv.Atoi(topicId)
+}
+
+func isTopicError(err error) bool {
+	return errors.Is(err, core.ErrTopicRequired) ||
+		errors.Is(err, core.ErrTopicsDisabled) ||
+		errors.Is(err, core.ErrTopicNotFound)
+}
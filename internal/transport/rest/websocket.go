@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/Woodfyn/chat-api-backend-go/internal/core"
 
@@ -21,13 +22,27 @@ func (h *Handler) initWebSocketRouter(api *mux.Router) {
 		{
 			groupChat.HandleFunc("/create", h.wsCreateChatGroup).Methods(http.MethodPost)
 			groupChat.HandleFunc("/join", h.wsJoinChatGroup).Methods(http.MethodPost)
+			groupChat.HandleFunc("/add", h.wsAddChatGroupMember).Methods(http.MethodPost)
 			groupChat.HandleFunc("/leave/{chatId}", h.wsLeaveChatGroup).Methods(http.MethodDelete)
 
 			admin := groupChat.PathPrefix("/admin").Subrouter()
 			{
 				admin.HandleFunc("/update", h.wsUpdateChatGroupAdmin).Methods(http.MethodPut)
 				admin.HandleFunc("/update/name", h.wsUpdateChatGroupName).Methods(http.MethodPut)
+				admin.HandleFunc("/update/description", h.wsUpdateChatGroupDescription).Methods(http.MethodPut)
+				admin.HandleFunc("/update/settings", h.wsUpdateChatGroupSettings).Methods(http.MethodPut)
+				admin.HandleFunc("/avatar/upload/{chatId}", h.wsUploadChatAvatar).Methods(http.MethodPost)
+				admin.HandleFunc("/avatar/delete/{chatId}", h.wsDeleteChatAvatar).Methods(http.MethodDelete)
 				admin.HandleFunc("/delete/{chatId}", h.wsDeleteChatGroup).Methods(http.MethodDelete)
+				admin.HandleFunc("/leave", h.wsLeaveChatGroupAdmin).Methods(http.MethodPost)
+				admin.HandleFunc("/topic/create", h.wsCreateChatTopic).Methods(http.MethodPost)
+
+				requests := admin.PathPrefix("/requests").Subrouter()
+				{
+					requests.HandleFunc("/get/{chatId}", h.wsGetJoinRequests).Methods(http.MethodGet)
+					requests.HandleFunc("/approve", h.wsApproveJoinRequest).Methods(http.MethodPost)
+					requests.HandleFunc("/decline", h.wsDeclineJoinRequest).Methods(http.MethodPost)
+				}
 			}
 		}
 
@@ -37,12 +52,36 @@ func (h *Handler) initWebSocketRouter(api *mux.Router) {
 			defaultChat.HandleFunc("/delete/{chatId}", h.wsDeleteChatDefault).Methods(http.MethodDelete)
 		}
 
+		chat.HandleFunc("/saved", h.wsSavedChat).Methods(http.MethodGet)
+
+		h.initChannelRouter(chat)
+
 		chat.HandleFunc("/wall", h.wsWall).Methods(http.MethodGet)
 
+		prefs := chat.PathPrefix("/prefs").Subrouter()
+		{
+			prefs.HandleFunc("/update", h.wsUpdateChatPrefs).Methods(http.MethodPut)
+			prefs.HandleFunc("/pins/reorder", h.wsReorderPinnedChats).Methods(http.MethodPut)
+		}
+
+		folder := chat.PathPrefix("/folder").Subrouter()
+		{
+			folder.HandleFunc("/create", h.wsCreateChatFolder).Methods(http.MethodPost)
+			folder.HandleFunc("/get", h.wsGetChatFolders).Methods(http.MethodGet)
+			folder.HandleFunc("/update", h.wsUpdateChatFolder).Methods(http.MethodPut)
+			folder.HandleFunc("/delete/{folderId}", h.wsDeleteChatFolder).Methods(http.MethodDelete)
+			folder.HandleFunc("/reorder", h.wsReorderChatFolders).Methods(http.MethodPut)
+		}
+		chat.HandleFunc("/{chatId:[0-9]+}", h.wsChatInfo).Methods(http.MethodGet)
+		chat.HandleFunc("/topic/get/{chatId}", h.wsGetChatTopics).Methods(http.MethodGet)
+
 		msg := chat.PathPrefix("/message").Subrouter()
 		{
 			msg.HandleFunc("/send", h.wsSendMessage).Methods(http.MethodPost)
+			msg.HandleFunc("/forward", h.wsForwardMessage).Methods(http.MethodPost)
 			msg.HandleFunc("/get/{chatId}", h.wsGetMessages).Methods(http.MethodGet)
+			msg.HandleFunc("/read", h.wsReadMessages).Methods(http.MethodPost)
+			msg.HandleFunc("/search/{chatId}", h.wsSearchMessages).Methods(http.MethodGet)
 		}
 	}
 
@@ -101,12 +140,12 @@ func (h *Handler) wsCreateChatGroup(w http.ResponseWriter, r *http.Request) {
 // @Summary CreateChatDefault
 // @Tags Chat
 // @Security ApiKeyAuth
-// @Description create chat default
+// @Description get or create the direct chat with a user found by phone or username
 // @ID createChatDefault
 // @Accept json
 // @Produce json
 // @Param input body core.CreateDefaultChatReq true "create chat default"
-// @Success 200
+// @Success 200 {object} core.ChatIDResp
 // @Failure 400,500 {object} errorResponse
 // @Router /api/chat/default/create [post]
 func (h *Handler) wsCreateChatDefault(w http.ResponseWriter, r *http.Request) {
@@ -135,9 +174,16 @@ func (h *Handler) wsCreateChatDefault(w http.ResponseWriter, r *http.Request) {
 
 	defer r.Body.Close()
 
-	if err = h.wsService.CreateChatDefault(r.Context(), req, userId); err != nil {
-		if errors.Is(err, core.ErrDuplicatedKey) {
-			h.newErrorResponse(w, http.StatusBadRequest, core.ErrThisCredIsAlready.Error())
+	chatId, err := h.wsService.CreateChatDefault(r.Context(), req, userId)
+	if err != nil {
+		if errors.Is(err, core.ErrRecordNotFound) {
+			h.newErrorResponse(w, http.StatusBadRequest, core.ErrUserNotFound.Error())
+			return
+		} else if errors.Is(err, core.ErrCannotChatWithSelf) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		} else if errors.Is(err, core.ErrBlockedByUser) {
+			h.newErrorResponse(w, http.StatusForbidden, err.Error())
 			return
 		}
 
@@ -145,7 +191,36 @@ func (h *Handler) wsCreateChatDefault(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.newResponse(w, http.StatusOK, nil)
+	h.newResponse(w, http.StatusOK, core.ChatIDResp{
+		ChatID: chatId,
+	})
+}
+
+// @Summary SavedChat
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description get or create the saved messages chat
+// @ID savedChat
+// @Produce json
+// @Success 200 {object} core.ChatIDResp
+// @Failure 400,500 {object} errorResponse
+// @Router /api/chat/saved [get]
+func (h *Handler) wsSavedChat(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	chatId, err := h.wsService.GetSavedChat(r.Context(), userId)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.newResponse(w, http.StatusOK, core.ChatIDResp{
+		ChatID: chatId,
+	})
 }
 
 // @Summary JoinChat
@@ -204,11 +279,17 @@ func (h *Handler) wsJoinChatGroup(w http.ResponseWriter, r *http.Request) {
 				ReceiveUserID: chatUser.UserID,
 			}
 
-			h.wsHandler.AddEvent(chatUser.UserID, event)
+			h.sendEvent(event)
 		}
 
 		h.newResponse(w, http.StatusOK, nil)
 		return
+	case core.ErrJoinApprovalRequired:
+		h.wsRequestJoinChatGroup(w, r, &core.ChatUser{
+			UserID: userId,
+			ChatID: req.ChatID,
+		})
+		return
 	case core.ErrChatGroupFull, core.ErrConnotJoinChat, core.ErrInvalideChatID:
 		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
 		return
@@ -218,6 +299,77 @@ func (h *Handler) wsJoinChatGroup(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// @Summary AddChatGroupMember
+// @Tags Chat
+// @Security ApiKeyAuth
+// @Description add another user to a group
+// @ID addChatGroupMember
+// @Accept json
+// @Produce json
+// @Param input body core.AddChatGroupMemberReq true "group and user"
+// @Success 200
+// @Failure 400,403,500 {object} errorResponse
+// @Router /api/chat/group/add [post]
+func (h *Handler) wsAddChatGroupMember(w http.ResponseWriter, r *http.Request) {
+	userId, ok := r.Context().Value("userId").(int)
+	if !ok {
+		h.newErrorResponse(w, http.StatusBadRequest, core.ErrEmptyUserID.Error())
+		return
+	}
+
+	var req *core.AddChatGroupMemberReq
+	reqBytes, err := io.ReadAll(r.Body)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := json.Unmarshal(reqBytes, &req); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	defer r.Body.Close()
+
+	msg, err := h.wsService.AddChatGroupMember(r.Context(), req, userId)
+	if err != nil {
+		switch {
+		case errors.Is(err, core.ErrNotChatMember), errors.Is(err, core.ErrJoinApprovalRequired),
+			errors.Is(err, core.ErrBlockedByUser), errors.Is(err, core.ErrPrivacyRestricted):
+			h.newErrorResponse(w, http.StatusForbidden, err.Error())
+		case errors.Is(err, core.ErrRecordNotFound):
+			h.newErrorResponse(w, http.StatusBadRequest, core.ErrUserNotFound.Error())
+		case errors.Is(err, core.ErrChatGroupFull), errors.Is(err, core.ErrConnotJoinChat),
+			errors.Is(err, core.ErrInvalideChatID), errors.Is(err, core.ErrJoinIsAlready):
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		default:
+			h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	chatUsers, err := h.wsService.GetUserOnChat(r.Context(), req.ChatID)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for _, chatUser := range chatUsers {
+		h.sendEvent(&core.Event{
+			Header:        core.JoinChatEventHeader,
+			Message:       msg,
+			ReceiveUserID: chatUser.UserID,
+		})
+	}
+
+	h.newResponse(w, http.StatusOK, nil)
+}
+
 // @Summary LeaveChatGroup
 // @Tags Chat
 // @Security ApiKeyAuth
@@ -255,6 +407,11 @@ func (h *Handler) wsLeaveChatGroup(w http.ResponseWriter, r *http.Request) {
 		ChatID: chatId,
 	})
 	if err != nil {
+		if errors.Is(err, core.ErrInvalideChatID) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
 		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -272,7 +429,7 @@ func (h *Handler) wsLeaveChatGroup(w http.ResponseWriter, r *http.Request) {
 			ReceiveUserID: chatUser.UserID,
 		}
 
-		h.wsHandler.AddEvent(chatUser.UserID, event)
+		h.sendEvent(event)
 	}
 
 	h.newResponse(w, http.StatusOK, nil)
@@ -414,9 +571,17 @@ func (h *Handler) wsUpdateChatGroupName(w http.ResponseWriter, r *http.Request)
 			ReceiveUserID: chatUser.UserID,
 		}
 
-		h.wsHandler.AddEvent(chatUser.UserID, event)
+		h.sendEvent(event)
 	}
 
+	info, err := h.wsService.GetChatInfo(r.Context(), req.ChatID)
+	if err != nil {
+		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.notifyChatMetadataUpdated(cahtUsers, info)
+
 	h.newResponse(w, http.StatusOK, nil)
 }
 
@@ -485,7 +650,7 @@ func (h *Handler) wsUpdateChatGroupAdmin(w http.ResponseWriter, r *http.Request)
 			ReceiveUserID: chatUser.UserID,
 		}
 
-		h.wsHandler.AddEvent(chatUser.UserID, event)
+		h.sendEvent(event)
 	}
 
 	h.newResponse(w, http.StatusOK, nil)
@@ -494,11 +659,13 @@ func (h *Handler) wsUpdateChatGroupAdmin(w http.ResponseWriter, r *http.Request)
 // @Summary ChatWall
 // @Tags Chat
 // @Security ApiKeyAuth
-// @Description get chat wall
+// @Description get chat wall, pinned chats go first
 // @ID chatWall
 // @Produce json
+// @Param archived query bool false "list archived chats instead"
+// @Param folder_id query int false "list only chats of the folder"
 // @Success 200 {array} core.WallChatResp
-// @Failure 400,500 {object} errorResponse
+// @Failure 400,404,500 {object} errorResponse
 // @Router /api/chat/wall [get]
 func (h *Handler) wsWall(w http.ResponseWriter, r *http.Request) {
 	userId, ok := r.Context().Value("userId").(int)
@@ -507,8 +674,25 @@ func (h *Handler) wsWall(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response, err := h.wsService.GetWall(r.Context(), userId)
+	archived := r.URL.Query().Get("archived") == "true"
+
+	var folderId int
+	if value := r.URL.Query().Get("folder_id"); value != "" {
+		var err error
+		folderId, err = strconv.Atoi(value)
+		if err != nil {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	response, err := h.wsService.GetWall(r.Context(), userId, archived, folderId)
 	if err != nil {
+		if errors.Is(err, core.ErrFolderNotFound) {
+			h.newErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
 		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -573,18 +757,36 @@ func (h *Handler) wsSendMessage(w http.ResponseWriter, r *http.Request) {
 
 	msg, err := h.wsService.SendMessage(r.Context(), req, userId)
 	if err != nil {
+		if errors.Is(err, core.ErrOnlyAdminCanPost) || errors.Is(err, core.ErrNotChatMember) || errors.Is(err, core.ErrBlockedByUser) {
+			h.newErrorResponse(w, http.StatusForbidden, err.Error())
+			return
+		} else if isTopicError(err) {
+			h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		var slowModeErr *core.SlowModeError
+		if errors.As(err, &slowModeErr) {
+			w.Header().Set("Retry-After", strconv.Itoa(slowModeErr.Seconds()))
+			h.newErrorResponse(w, http.StatusTooManyRequests, err.Error())
+			return
+		}
+
 		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
+	now := time.Now()
+
 	for _, chatUser := range chatUsers {
 		event := &core.Event{
 			Header:        core.NewMessageEventHeader,
 			Message:       msg,
+			Silent:        chatUser.IsMuted(now),
 			ReceiveUserID: chatUser.UserID,
 		}
 
-		h.wsHandler.AddEvent(chatUser.UserID, event)
+		h.sendEvent(event)
 	}
 
 	h.newResponse(w, http.StatusOK, nil)
@@ -597,8 +799,9 @@ func (h *Handler) wsSendMessage(w http.ResponseWriter, r *http.Request) {
 // @ID getMessages
 // @Produce json
 // @Param roomId path string true "chat id"
+// @Param topic_id query int false "list only messages of the topic"
 // @Seccess 200 {array} core.ChatMessage
-// @Failure 400,500 {object} errorResponse
+// @Failure 400,404,500 {object} errorResponse
 // @Router /api/chat/message/get/{chatId} [get]
 func (h *Handler) wsGetMessages(w http.ResponseWriter, r *http.Request) {
 	userId, ok := r.Context().Value("userId").(int)
@@ -613,14 +816,25 @@ func (h *Handler) wsGetMessages(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	messages, err := h.wsService.GetMessages(r.Context(), chatId)
+	topicId, err := getTopicIdFromQuery(r)
 	if err != nil {
+		h.newErrorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	messages, err := h.wsService.GetMessages(r.Context(), chatId, topicId, userId)
+	if err != nil {
+		if errors.Is(err, core.ErrTopicNotFound) {
+			h.newErrorResponse(w, http.StatusNotFound, err.Error())
+			return
+		}
+
 		h.newErrorResponse(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	for _, message := range messages {
-		if message.ID == userId {
+		if message.UserID == userId {
 			message.Username = "You"
 		}
 	}
@@ -2,54 +2,105 @@ package websocket
 
 import (
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/Woodfyn/chat-api-backend-go/internal/core"
 	"github.com/gorilla/websocket"
 )
 
+const (
+	// pongWait is how long a client may stay silent before its stream is
+	// closed, pings are sent every pingPeriod so a live client always
+	// answers in time.
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+	writeWait  = 10 * time.Second
+)
+
 type Client struct {
 	conn *websocket.Conn
 
-	userId int
+	userId    int
+	sessionId int
 
 	eventCh chan *core.Event
 	exitCh  chan struct{}
+
+	closeOnce sync.Once
 }
 
-func (h *Handler) initClient(w http.ResponseWriter, r *http.Request, userId int) (*Client, error) {
+func (h *Handler) initClient(w http.ResponseWriter, r *http.Request, userId, sessionId int) (*Client, error) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		panic(err)
 	}
 
-	wscFirst, ok := h.ConnMap[userId]
-	if !ok {
-		wscSecond := &Client{
-			conn: conn,
+	wsc := &Client{
+		conn: conn,
 
-			userId: userId,
+		userId:    userId,
+		sessionId: sessionId,
 
-			eventCh: make(chan *core.Event),
-			exitCh:  make(chan struct{}),
-		}
+		eventCh: make(chan *core.Event),
+		exitCh:  make(chan struct{}),
+	}
 
-		h.ConnMap[userId] = wscSecond
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-		return wscSecond, nil
+	clients, ok := h.ConnMap[userId]
+	if !ok {
+		clients = make(map[int]*Client)
+		h.ConnMap[userId] = clients
 	}
 
-	return wscFirst, nil
+	// A session reconnecting replaces its previous stream.
+	if prev, ok := clients[sessionId]; ok {
+		prev.closeConn()
+	}
+
+	clients[sessionId] = wsc
+
+	go wsc.readLoop()
+
+	return wsc, nil
+}
+
+// readLoop reads the connection until it fails, the client only sends
+// pongs and close frames. A client that stops answering pings is closed
+// once pongWait has passed.
+func (c *Client) readLoop() {
+	defer c.closeConn()
+
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
 }
 
+// closeConn is safe to call several times, every stream of the client
+// returns once it is closed.
 func (c *Client) closeConn() {
-	close(c.eventCh)
-	close(c.exitCh)
-	c.conn.Close()
+	c.closeOnce.Do(func() {
+		close(c.exitCh)
+		c.conn.Close()
+	})
 }
 
-func (c *Client) writeMessage(messageType int, data []byte) {
+func (c *Client) writeMessage(messageType int, data []byte) error {
+	c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+
 	if err := c.conn.WriteMessage(messageType, data); err != nil {
 		c.closeConn()
-		panic(err)
+		return err
 	}
+
+	return nil
 }
@@ -4,6 +4,8 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/Woodfyn/chat-api-backend-go/internal/core"
 	"github.com/gorilla/websocket"
@@ -31,36 +33,53 @@ type Encoder interface {
 type Handler struct {
 	encoder Encoder
 
-	ConnMap map[int]*Client
+	// ConnMap holds the clients of every user by their session id.
+	ConnMap map[int]map[int]*Client
+	mu      sync.Mutex
 }
 
 func NewWebSocketHandler(encoder Encoder) *Handler {
 	return &Handler{
 		encoder: encoder,
 
-		ConnMap: make(map[int]*Client),
+		ConnMap: make(map[int]map[int]*Client),
 	}
 }
 
-func (h *Handler) Stream(w http.ResponseWriter, r *http.Request, userId int) {
-	wsc, err := h.initClient(w, r, userId)
+func (h *Handler) Stream(w http.ResponseWriter, r *http.Request, userId, sessionId int) {
+	wsc, err := h.initClient(w, r, userId, sessionId)
 	if err != nil {
 		panic(err)
 	}
+	defer h.removeClient(wsc)
+
+	ping := time.NewTicker(pingPeriod)
+	defer ping.Stop()
 
 	for {
 		select {
 		case <-wsc.exitCh:
 			return
+		case <-ping.C:
+			if err := wsc.writeMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
 		case event := <-wsc.eventCh:
 			if event.ReceiveUserID == wsc.userId {
-				if event.Message.UserID == wsc.userId {
-					event.Message.Username = "You"
+				// The event is shared by every stream of the user, so the
+				// message is copied before it is changed.
+				message := event.Message
+				if message != nil && message.UserID == wsc.userId {
+					own := *message
+					own.Username = "You"
+					message = &own
 				}
 
 				eventRespBytes, err := json.Marshal(core.EventResponse{
 					Header:  event.Header,
-					Message: event.Message,
+					Message: message,
+					Payload: event.Payload,
+					Silent:  event.Silent,
 				})
 				if err != nil {
 					wsc.closeConn()
@@ -73,38 +92,92 @@ func (h *Handler) Stream(w http.ResponseWriter, r *http.Request, userId int) {
 					panic(err)
 				}
 
-				wsc.writeMessage(websocket.TextMessage, []byte(base64.StdEncoding.EncodeToString(encrypt)))
+				if err := wsc.writeMessage(websocket.TextMessage, []byte(base64.StdEncoding.EncodeToString(encrypt))); err != nil {
+					return
+				}
 			}
-		default:
-			continue
 		}
 	}
 }
 
+// removeClient forgets the client once its stream has returned, unless it
+// was already replaced.
+func (h *Handler) removeClient(wsc *Client) {
+	wsc.closeConn()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	clients := h.ConnMap[wsc.userId]
+	if clients[wsc.sessionId] != wsc {
+		return
+	}
+
+	delete(clients, wsc.sessionId)
+	if len(clients) == 0 {
+		delete(h.ConnMap, wsc.userId)
+	}
+}
+
+// StopStream closes every stream of the user, a user without an open
+// stream is skipped.
 func (h *Handler) StopStream(userId int) {
-	wsc, ok := h.ConnMap[userId]
-	if !ok {
-		panic(core.ErrStreamNotAvailable)
+	h.mu.Lock()
+	clients := h.ConnMap[userId]
+	delete(h.ConnMap, userId)
+	h.mu.Unlock()
+
+	for _, wsc := range clients {
+		wsc.closeConn()
 	}
+}
 
-	wsc.exitCh <- struct{}{}
+// StopSessionStream closes the streams of the user opened by one of the
+// given sessions, the streams of other sessions stay open.
+func (h *Handler) StopSessionStream(userId int, sessionIds ...int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-	wsc.closeConn()
+	clients, ok := h.ConnMap[userId]
+	if !ok {
+		return
+	}
 
-	delete(h.ConnMap, userId)
+	for _, sessionId := range sessionIds {
+		if wsc, ok := clients[sessionId]; ok {
+			wsc.closeConn()
+			delete(clients, sessionId)
+		}
+	}
+
+	if len(clients) == 0 {
+		delete(h.ConnMap, userId)
+	}
 }
 
 func (wsh *Handler) OnlineStream(userId int) bool {
+	wsh.mu.Lock()
+	defer wsh.mu.Unlock()
+
 	_, ok := wsh.ConnMap[userId]
 
 	return ok
 }
 
+// AddEvent delivers the event to every stream of the user, a user without
+// an open stream is skipped.
 func (wsh *Handler) AddEvent(userId int, event *core.Event) {
-	wsc, ok := wsh.ConnMap[userId]
-	if !ok {
-		panic(core.ErrStreamNotAvailable)
+	wsh.mu.Lock()
+	clients := make([]*Client, 0, len(wsh.ConnMap[userId]))
+	for _, wsc := range wsh.ConnMap[userId] {
+		clients = append(clients, wsc)
 	}
+	wsh.mu.Unlock()
 
-	wsc.eventCh <- event
+	for _, wsc := range clients {
+		select {
+		case wsc.eventCh <- event:
+		case <-wsc.exitCh:
+		}
+	}
 }
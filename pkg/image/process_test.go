@@ -0,0 +1,132 @@
+package image_test
+
+import (
+	"bytes"
+	goimage "image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/Woodfyn/chat-api-backend-go/pkg/image"
+)
+
+// halves returns a w x h image with a red left half and a blue right half.
+func halves(w, h int) goimage.Image {
+	img := goimage.NewRGBA(goimage.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if x < w/2 {
+				img.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+
+	return img
+}
+
+func encodePNG(t *testing.T, img goimage.Image) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Error when encoding png: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+// encodeJPEG encodes the image with an EXIF segment holding the orientation.
+func encodeJPEG(t *testing.T, img goimage.Image, orientation byte) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("Error when encoding jpeg: %v", err)
+	}
+
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08,
+		0x00, 0x01,
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, orientation, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00,
+	}
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := append([]byte{0xFF, 0xE1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}, payload...)
+
+	data := buf.Bytes()
+	return append(append([]byte{0xFF, 0xD8}, segment...), data[2:]...)
+}
+
+func TestProcessAvatar(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    func(t *testing.T) []byte
+		sizes   []int
+		wantErr error
+		// wantRed tells whether the top right corner of the result is red
+		wantRed bool
+	}{
+		{
+			name:    "png",
+			data:    func(t *testing.T) []byte { return encodePNG(t, halves(300, 200)) },
+			sizes:   []int{64, 256},
+			wantRed: false,
+		},
+		{
+			name:    "jpeg upright",
+			data:    func(t *testing.T) []byte { return encodeJPEG(t, halves(300, 200), 1) },
+			sizes:   []int{64},
+			wantRed: false,
+		},
+		{
+			name:    "jpeg rotated",
+			data:    func(t *testing.T) []byte { return encodeJPEG(t, halves(300, 200), 6) },
+			sizes:   []int{64},
+			wantRed: true,
+		},
+		{
+			name:    "not an image",
+			data:    func(t *testing.T) []byte { return []byte("definitely not an image") },
+			sizes:   []int{64},
+			wantErr: image.ErrInvalidImage,
+		},
+		{
+			name:    "truncated",
+			data:    func(t *testing.T) []byte { return encodePNG(t, halves(300, 200))[:64] },
+			sizes:   []int{64},
+			wantErr: image.ErrInvalidImage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			images, err := image.ProcessAvatar(tt.data(t), tt.sizes)
+			if err != tt.wantErr {
+				t.Fatalf("ProcessAvatar() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			if len(images) != len(tt.sizes) {
+				t.Fatalf("Expected %d images, got %d", len(tt.sizes), len(images))
+			}
+
+			for _, size := range tt.sizes {
+				img, err := jpeg.Decode(bytes.NewReader(images[size]))
+				if err != nil {
+					t.Fatalf("Error when decoding the %d image: %v", size, err)
+				}
+
+				if bounds := img.Bounds(); bounds.Dx() != size || bounds.Dy() != size {
+					t.Errorf("Expected %dx%d image, got %dx%d", size, size, bounds.Dx(), bounds.Dy())
+				}
+
+				r, _, b, _ := img.At(size-1, 0).RGBA()
+				if red := r > b; red != tt.wantRed {
+					t.Errorf("Expected red top right corner = %v, got r=%d b=%d", tt.wantRed, r, b)
+				}
+			}
+		})
+	}
+}
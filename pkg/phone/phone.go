@@ -0,0 +1,52 @@
+package phone
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+var separators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+
+// Normalize brings a phone number to the E.164 form, e.g. "+380 (67) 123-45-67"
+// becomes "+380671234567". A leading 00 is treated as the international prefix.
+func Normalize(raw string) (string, error) {
+	number := separators.Replace(strings.TrimSpace(raw))
+
+	if strings.HasPrefix(number, "00") {
+		number = "+" + number[2:]
+	}
+
+	if !strings.HasPrefix(number, "+") {
+		return "", ErrInvalidPhone
+	}
+
+	digits := number[1:]
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return "", ErrInvalidPhone
+	}
+
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", ErrInvalidPhone
+		}
+	}
+
+	return number, nil
+}
+
+// Hash returns the hex encoded sha256 of the salt followed by the
+// normalized phone number. Numbers that cannot be normalized are hashed as is.
+func Hash(salt, raw string) string {
+	number, err := Normalize(raw)
+	if err != nil {
+		number = raw
+	}
+
+	sum := sha256.Sum256([]byte(salt + number))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,79 @@
+package phone_test
+
+import (
+	"testing"
+
+	"github.com/Woodfyn/chat-api-backend-go/pkg/phone"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "e164",
+			raw:  "+380671234567",
+			want: "+380671234567",
+		},
+		{
+			name: "separators",
+			raw:  " +380 (67) 123-45.67 ",
+			want: "+380671234567",
+		},
+		{
+			name: "international prefix",
+			raw:  "00380671234567",
+			want: "+380671234567",
+		},
+		{
+			name:    "no country code",
+			raw:     "0671234567",
+			wantErr: true,
+		},
+		{
+			name:    "letters",
+			raw:     "+38067123456a",
+			wantErr: true,
+		},
+		{
+			name:    "too short",
+			raw:     "+3801234",
+			wantErr: true,
+		},
+		{
+			name:    "too long",
+			raw:     "+3806712345678901",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := phone.Normalize(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Normalize() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("Normalize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHash(t *testing.T) {
+	if phone.Hash("salt", "+380 67 123 45 67") != phone.Hash("salt", "+380671234567") {
+		t.Error("Hash() differs for the same number in different formats")
+	}
+
+	if phone.Hash("salt", "+380671234567") == phone.Hash("pepper", "+380671234567") {
+		t.Error("Hash() does not depend on the salt")
+	}
+
+	if len(phone.Hash("salt", "+380671234567")) != 64 {
+		t.Error("Hash() is not a hex encoded sha256")
+	}
+}
@@ -1,83 +1,218 @@
 package token
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"math/rand"
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type TokenManager interface {
-	NewJWT(userId string, ttl time.Duration) (string, error)
-	Parse(accessToken string) (string, error)
+	NewJWT(userId, sessionId string, ttl time.Duration) (string, error)
+	Parse(accessToken string) (*Claims, error)
 	NewRefreshToken() (string, error)
 	IsTokenExpired(accessToken string) bool
+	JWKS() *JWKS
 }
 
+// claims carry the session the access token was issued for next to the
+// standard ones, tokens issued before sessions were tracked have no sid.
+type claims struct {
+	jwt.RegisteredClaims
+	SessionID string `json:"sid,omitempty"`
+}
+
+// Claims describe a verified access token. Tokens issued before they could
+// be revoked have no TokenID and a zero IssuedAt.
+type Claims struct {
+	UserID    string
+	SessionID string
+	TokenID   string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// Manager signs access tokens with the active key and puts its id in the
+// kid header. Tokens are verified with whichever key their kid names, so
+// rotating the active key does not log anybody out.
 type Manager struct {
-	signingKey string
+	signingKey *Key
+	keys       map[string]*Key
+	publicKeys []*Key
+
+	// legacySecret verifies the HS256 tokens without a kid issued before the
+	// asymmetric keys until legacyUntil, without any keys it also signs new
+	// tokens and is never cut off.
+	legacySecret []byte
+	legacyUntil  time.Time
 }
 
-func NewManager(signingKey string) (*Manager, error) {
-	if signingKey == "" {
-		return nil, errors.New("empty signing key")
+// NewManager verifies legacy HS256 tokens next to the keys only before
+// legacyUntil, which should be at least one access token TTL after the
+// keys were rolled out. The zero time refuses legacy tokens right away.
+func NewManager(keys []*Key, activeKeyId, legacySecret string, legacyUntil time.Time) (*Manager, error) {
+	m := &Manager{
+		keys:         make(map[string]*Key, len(keys)),
+		publicKeys:   keys,
+		legacySecret: []byte(legacySecret),
+		legacyUntil:  legacyUntil,
+	}
+
+	if len(keys) == 0 {
+		if legacySecret == "" {
+			return nil, errors.New("empty signing key")
+		}
+
+		return m, nil
 	}
 
-	return &Manager{signingKey: signingKey}, nil
+	for _, key := range keys {
+		if _, ok := m.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicated key id %q", key.ID)
+		}
+
+		m.keys[key.ID] = key
+	}
+
+	active, ok := m.keys[activeKeyId]
+	if !ok {
+		return nil, fmt.Errorf("unknown active key id %q", activeKeyId)
+	} else if active.private == nil {
+		return nil, fmt.Errorf("active key %q has no private key", activeKeyId)
+	}
+
+	m.signingKey = active
+
+	return m, nil
 }
 
-func (m *Manager) NewJWT(userId string, ttl time.Duration) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
-		ExpiresAt: time.Now().Add(ttl).Unix(),
-		Subject:   userId,
-	})
+// NewJWT issues an access token with a random jti, so the token can be
+// revoked on its own.
+func (m *Manager) NewJWT(userId, sessionId string, ttl time.Duration) (string, error) {
+	tokenId := make([]byte, 16)
+	if _, err := rand.Read(tokenId); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+
+	claims := claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(tokenId),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			Subject:   userId,
+		},
+		SessionID: sessionId,
+	}
 
-	return token.SignedString([]byte(m.signingKey))
+	if m.signingKey == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.legacySecret)
+	}
+
+	token := jwt.NewWithClaims(m.signingKey.method, claims)
+	token.Header["kid"] = m.signingKey.ID
+
+	return token.SignedString(m.signingKey.private)
 }
 
-func (m *Manager) Parse(accessToken string) (string, error) {
-	token, err := jwt.Parse(accessToken, func(token *jwt.Token) (i interface{}, err error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+// Parse verifies the access token and returns its claims.
+func (m *Manager) Parse(accessToken string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(accessToken, &claims{}, m.verificationKey)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenClaims, ok := token.Claims.(*claims)
+	if !ok {
+		return nil, fmt.Errorf("error get user claims from token")
+	}
+
+	parsed := &Claims{
+		UserID:    tokenClaims.Subject,
+		SessionID: tokenClaims.SessionID,
+		TokenID:   tokenClaims.ID,
+	}
+
+	if tokenClaims.IssuedAt != nil {
+		parsed.IssuedAt = tokenClaims.IssuedAt.Time
+	}
+
+	if tokenClaims.ExpiresAt != nil {
+		parsed.ExpiresAt = tokenClaims.ExpiresAt.Time
+	}
+
+	return parsed, nil
+}
+
+// verificationKey picks the key named by the kid header, the algorithm has
+// to be the one of the key.
+func (m *Manager) verificationKey(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || len(m.legacySecret) == 0 {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 
-		return []byte(m.signingKey), nil
-	})
-	if err != nil {
-		return "", err
+		if m.signingKey != nil && !time.Now().Before(m.legacyUntil) {
+			return nil, errors.New("legacy tokens are no longer accepted")
+		}
+
+		return m.legacySecret, nil
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
+	key, ok := m.keys[kid]
 	if !ok {
-		return "", fmt.Errorf("error get user claims from token")
+		return nil, fmt.Errorf("unknown key id: %q", kid)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 	}
 
-	return claims["sub"].(string), nil
+	return key.public, nil
 }
 
+// IsTokenExpired reports whether the access token is still valid.
 func (m *Manager) IsTokenExpired(accessToken string) bool {
-	token, err := jwt.Parse(accessToken, func(token *jwt.Token) (i interface{}, err error) {
-		return []byte(m.signingKey), nil
-	})
+	_, err := m.Parse(accessToken)
 
-	if err != nil {
-		return false
+	return err == nil
+}
+
+// JWKS returns the public keys that verify access tokens, the legacy
+// secret is never published.
+func (m *Manager) JWKS() *JWKS {
+	jwks := &JWKS{
+		Keys: make([]*JWK, 0, len(m.publicKeys)),
 	}
 
-	return token.Valid
+	for _, key := range m.publicKeys {
+		jwks.Keys = append(jwks.Keys, key.jwk())
+	}
+
+	return jwks
 }
 
+// NewRefreshToken returns 32 bytes read from crypto/rand encoded as hex.
 func (m *Manager) NewRefreshToken() (string, error) {
 	b := make([]byte, 32)
 
-	s := rand.NewSource(time.Now().Unix())
-	r := rand.New(s)
-
-	if _, err := r.Read(b); err != nil {
+	if _, err := rand.Read(b); err != nil {
 		return "", err
 	}
 
-	return fmt.Sprintf("%x", b), nil
+	return hex.EncodeToString(b), nil
+}
+
+// HashRefreshToken returns the hex encoded SHA-256 of the refresh token, only
+// the hash is stored. Refresh tokens are random enough not to need a salt.
+func HashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,237 @@
+package token_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/Woodfyn/chat-api-backend-go/pkg/token"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func rsaKey(t *testing.T, id string) (*token.Key, *rsa.PrivateKey) {
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Error when generating rsa key: %v", err)
+	}
+
+	key, err := token.ParseKey(id, pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(private),
+	}))
+	if err != nil {
+		t.Fatalf("Error when parsing rsa key: %v", err)
+	}
+
+	return key, private
+}
+
+func ed25519Key(t *testing.T, id string, publicOnly bool) *token.Key {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Error when generating ed25519 key: %v", err)
+	}
+
+	block := &pem.Block{Type: "PRIVATE KEY"}
+	if publicOnly {
+		block.Type = "PUBLIC KEY"
+		block.Bytes, err = x509.MarshalPKIXPublicKey(public)
+	} else {
+		block.Bytes, err = x509.MarshalPKCS8PrivateKey(private)
+	}
+	if err != nil {
+		t.Fatalf("Error when marshaling ed25519 key: %v", err)
+	}
+
+	key, err := token.ParseKey(id, pem.EncodeToMemory(block))
+	if err != nil {
+		t.Fatalf("Error when parsing ed25519 key: %v", err)
+	}
+
+	return key
+}
+
+func TestManagerRotation(t *testing.T) {
+	old, _ := rsaKey(t, "old")
+	current := ed25519Key(t, "current", false)
+
+	before, err := token.NewManager([]*token.Key{old}, "old", "", time.Time{})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	after, err := token.NewManager([]*token.Key{old, current}, "current", "", time.Time{})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	for name, manager := range map[string]*token.Manager{"rs256": before, "eddsa": after} {
+		accessToken, err := manager.NewJWT("7", "3", time.Minute)
+		if err != nil {
+			t.Fatalf("%s: NewJWT() error = %v", name, err)
+		}
+
+		claims, err := after.Parse(accessToken)
+		if err != nil {
+			t.Fatalf("%s: Parse() error = %v", name, err)
+		}
+
+		if claims.UserID != "7" || claims.SessionID != "3" {
+			t.Errorf("%s: Parse() = %s, %s, want 7, 3", name, claims.UserID, claims.SessionID)
+		}
+
+		if claims.TokenID == "" || claims.IssuedAt.IsZero() {
+			t.Errorf("%s: Parse() = %+v, want a jti and an iat", name, claims)
+		}
+	}
+
+	if jwks := after.JWKS(); len(jwks.Keys) != 2 || jwks.Keys[0].Kty != "RSA" || jwks.Keys[1].Crv != "Ed25519" {
+		t.Errorf("JWKS() = %+v, want the rsa and the ed25519 key", jwks.Keys)
+	}
+}
+
+func TestManagerRejects(t *testing.T) {
+	key, private := rsaKey(t, "main")
+
+	manager, err := token.NewManager([]*token.Key{key}, "main", "legacy", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	valid := time.Now().Add(time.Minute)
+
+	sign := func(method jwt.SigningMethod, kid string, signingKey any, exp time.Time) string {
+		t.Helper()
+
+		unsigned := jwt.NewWithClaims(method, jwt.MapClaims{"sub": "7", "exp": exp.Unix()})
+		if kid != "" {
+			unsigned.Header["kid"] = kid
+		}
+
+		signed, err := unsigned.SignedString(signingKey)
+		if err != nil {
+			t.Fatalf("Error when signing token: %v", err)
+		}
+
+		return signed
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatalf("Error when marshaling public key: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		accessToken string
+		wantErr     bool
+	}{
+		{
+			name:        "rs256",
+			accessToken: sign(jwt.SigningMethodRS256, "main", private, valid),
+		},
+		{
+			name:        "legacy hs256",
+			accessToken: sign(jwt.SigningMethodHS256, "", []byte("legacy"), valid),
+		},
+		{
+			name:        "wrong legacy secret",
+			accessToken: sign(jwt.SigningMethodHS256, "", []byte("other"), valid),
+			wantErr:     true,
+		},
+		{
+			name:        "unknown kid",
+			accessToken: sign(jwt.SigningMethodRS256, "missing", private, valid),
+			wantErr:     true,
+		},
+		{
+			name:        "hs256 signed with the public key",
+			accessToken: sign(jwt.SigningMethodHS256, "main", publicDER, valid),
+			wantErr:     true,
+		},
+		{
+			name:        "expired",
+			accessToken: sign(jwt.SigningMethodRS256, "main", private, time.Now().Add(-time.Minute)),
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := manager.Parse(tt.accessToken); (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestManagerLegacyCutOff(t *testing.T) {
+	key, _ := rsaKey(t, "main")
+
+	legacy, err := token.NewManager(nil, "", "legacy", time.Time{})
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	accessToken, err := legacy.NewJWT("7", "3", time.Minute)
+	if err != nil {
+		t.Fatalf("NewJWT() error = %v", err)
+	}
+
+	if _, err := legacy.Parse(accessToken); err != nil {
+		t.Errorf("Parse() without keys error = %v, want the secret to stay valid", err)
+	}
+
+	tests := []struct {
+		name        string
+		legacyUntil time.Time
+		wantErr     bool
+	}{
+		{
+			name:        "before the cut-off",
+			legacyUntil: time.Now().Add(time.Hour),
+		},
+		{
+			name:        "after the cut-off",
+			legacyUntil: time.Now().Add(-time.Hour),
+			wantErr:     true,
+		},
+		{
+			name:    "no cut-off",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, err := token.NewManager([]*token.Key{key}, "main", "legacy", tt.legacyUntil)
+			if err != nil {
+				t.Fatalf("NewManager() error = %v", err)
+			}
+
+			if _, err := manager.Parse(accessToken); (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewManagerActiveKey(t *testing.T) {
+	public := ed25519Key(t, "public", true)
+
+	if _, err := token.NewManager([]*token.Key{public}, "public", "", time.Time{}); err == nil {
+		t.Error("NewManager() accepted an active key without a private key")
+	}
+
+	if _, err := token.NewManager([]*token.Key{public}, "missing", "", time.Time{}); err == nil {
+		t.Error("NewManager() accepted an unknown active key")
+	}
+
+	if _, err := token.NewManager(nil, "", "", time.Time{}); err == nil {
+		t.Error("NewManager() accepted no keys and no secret")
+	}
+}
@@ -1,24 +1,20 @@
 package verife
 
 import (
-	"math/rand"
-	"time"
+	"crypto/rand"
+	"fmt"
+	"math/big"
 )
 
-var randowCodes = [...]byte{
-	'1', '2', '3', '4', '5', '6', '7', '8', '9', '0',
-}
-
-func GenereteCode() string {
-	var r *rand.Rand = rand.New(rand.NewSource(time.Now().UnixNano()))
-
-	var pwd []byte = make([]byte, 6)
-
-	for j := 0; j < 6; j++ {
-		index := r.Int() % len(randowCodes)
+// codeSpace is the number of distinct 6-digit codes.
+var codeSpace = big.NewInt(1_000_000)
 
-		pwd[j] = randowCodes[index]
+// GenereteCode returns a uniformly random 6-digit code read from crypto/rand.
+func GenereteCode() (string, error) {
+	n, err := rand.Int(rand.Reader, codeSpace)
+	if err != nil {
+		return "", err
 	}
 
-	return string(pwd)
+	return fmt.Sprintf("%06d", n.Int64()), nil
 }